@@ -0,0 +1,408 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned when logging through a Logger whose async queue was closed.
+var ErrClosed = errors.New("log: logger closed")
+
+// OverflowPolicy decides what happens to a message when the async queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes the caller wait for room in the queue.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest discards the message being logged.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest queued message to make room.
+	OverflowDropOldest
+	// OverflowKeepErrors discards every non-error message, and makes room for
+	// errors by evicting the oldest queued non-error message (or the oldest
+	// error if nothing else is queued).
+	OverflowKeepErrors
+)
+
+// AsyncOptions configures asynchronous delivery for a Logger.
+type AsyncOptions struct {
+	// QueueSize is the number of messages buffered before Overflow applies.
+	// Defaults to 1024.
+	QueueSize int
+	// Workers is the number of goroutines posting to Slack. Defaults to 1,
+	// which also keeps messages in order.
+	Workers int
+	// Overflow decides what happens when the queue is full. Defaults to OverflowBlock.
+	Overflow OverflowPolicy
+	// DropReportInterval is how often dropped messages are summarized in a
+	// single warning post. Defaults to one minute; unused with OverflowBlock.
+	DropReportInterval time.Duration
+}
+
+// job is a single message waiting to be posted.
+type job struct {
+	ctx     context.Context
+	lw      LogWriter
+	level   LogLevel
+	webhook string
+	payload Payload
+	// groups are the fan-out groups of the messages in the job; nil for a
+	// message posted to a single destination.
+	groups []*fanOutGroup
+}
+
+// finish reports err, the outcome of posting j, to its fan-out groups and
+// returns the error to record for it: err itself for a message posted to a
+// single destination, or a *FanOutError once the last destination of a
+// message posted to several has finished.
+func (j job) finish(err error) error {
+	if len(j.groups) == 0 {
+		return err
+	}
+	var result error
+	for _, g := range j.groups {
+		if g == nil {
+			if err != nil {
+				result = err
+			}
+			continue
+		}
+		if ferr := g.done(j.webhook, err); ferr != nil {
+			result = ferr
+		}
+	}
+	return result
+}
+
+// asyncQueue is a bounded in-memory queue drained by worker goroutines.
+type asyncQueue struct {
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	items    []job
+	size     int
+	// pending counts queued and in-flight jobs.
+	pending int
+	// idle is closed when pending drops to zero.
+	idle   chan struct{}
+	closed bool
+	err    error
+	wg     sync.WaitGroup
+
+	overflow OverflowPolicy
+	// dropped counts messages dropped since the last report, total since the start.
+	dropped [len(levelTags)]int
+	total   [len(levelTags)]int
+	// reportTo is the writer of the most recently dropped message.
+	reportTo LogWriter
+	stop     chan struct{}
+	stopOnce sync.Once
+	reported chan struct{}
+}
+
+func newAsyncQueue(opts AsyncOptions) *asyncQueue {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 1024
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	q := &asyncQueue{size: opts.QueueSize, overflow: opts.Overflow}
+	q.notEmpty = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
+	q.wg.Add(opts.Workers)
+	for range opts.Workers {
+		go q.worker()
+	}
+	if opts.Overflow != OverflowBlock {
+		if opts.DropReportInterval <= 0 {
+			opts.DropReportInterval = time.Minute
+		}
+		q.stop = make(chan struct{})
+		q.reported = make(chan struct{})
+		go q.reporter(opts.DropReportInterval)
+	}
+	return q
+}
+
+// enqueue adds j to the queue, applying the overflow policy while the queue is full.
+func (q *asyncQueue) enqueue(j job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	if len(q.items) >= q.size {
+		switch q.overflow {
+		case OverflowDropNewest:
+			q.drop(j)
+			return nil
+		case OverflowDropOldest:
+			q.drop(q.remove(0))
+		case OverflowKeepErrors:
+			if j.level != LevelError {
+				q.drop(j)
+				return nil
+			}
+			i := 0
+			for k, queued := range q.items {
+				if queued.level != LevelError {
+					i = k
+					break
+				}
+			}
+			q.drop(q.remove(i))
+		default:
+			for len(q.items) >= q.size && !q.closed {
+				q.notFull.Wait()
+			}
+			if q.closed {
+				return ErrClosed
+			}
+		}
+	}
+	q.items = append(q.items, j)
+	q.pending++
+	q.notEmpty.Signal()
+	return nil
+}
+
+// remove takes the queued job at index i out of the queue.
+// The caller must hold q.mu.
+func (q *asyncQueue) remove(i int) job {
+	j := q.items[i]
+	q.items = append(q.items[:i], q.items[i+1:]...)
+	q.pending--
+	return j
+}
+
+// drop counts j as dropped. The caller must hold q.mu.
+func (q *asyncQueue) drop(j job) {
+	q.dropped[j.level]++
+	q.total[j.level]++
+	q.reportTo = j.lw
+	if err := j.finish(nil); err != nil {
+		q.err = err
+	}
+}
+
+// reporter periodically posts a summary of dropped messages until the queue is closed.
+func (q *asyncQueue) reporter(interval time.Duration) {
+	defer close(q.reported)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			q.report()
+		case <-q.stop:
+			q.report()
+			return
+		}
+	}
+}
+
+// report posts a single warning summarizing the messages dropped since the last report.
+// It is rendered and routed like any other warning.
+func (q *asyncQueue) report() {
+	q.mu.Lock()
+	dropped := q.dropped
+	q.dropped = [len(levelTags)]int{}
+	lw := q.reportTo
+	q.mu.Unlock()
+
+	var (
+		n      int
+		counts []string
+	)
+	for level, count := range dropped {
+		if count > 0 {
+			n += count
+			counts = append(counts, fmt.Sprintf("%s: %d", levelTags[level], count))
+		}
+	}
+	if n == 0 {
+		return
+	}
+	// The summary is posted directly instead of queuing behind the backlog,
+	// and outside any thread.
+	lw.queue, lw.batch, lw.thread = nil, nil, nil
+	r := Record{
+		Level:   LevelWarning,
+		Message: fmt.Sprintf("%d messages dropped (%s)", n, strings.Join(counts, ", ")),
+		Prefix:  lw.prefix,
+		Time:    time.Now(),
+	}
+	dests, err := lw.destinations(r)
+	if err == nil {
+		err = lw.fanOut(dests, func(lw LogWriter, dest string) error {
+			return lw.outputTo(context.Background(), dest, r)
+		})
+	}
+	if err != nil {
+		q.setErr(err)
+	}
+}
+
+// worker posts jobs until the queue is closed and drained.
+func (q *asyncQueue) worker() {
+	defer q.wg.Done()
+	for {
+		q.mu.Lock()
+		for len(q.items) == 0 && !q.closed {
+			q.notEmpty.Wait()
+		}
+		if len(q.items) == 0 {
+			q.mu.Unlock()
+			return
+		}
+		j := q.items[0]
+		q.items[0] = job{}
+		q.items = q.items[1:]
+		q.notFull.Signal()
+		q.mu.Unlock()
+
+		q.done(j.finish(j.lw.send(j.ctx, j.level, j.webhook, j.payload)))
+	}
+}
+
+// done records the outcome of a job and wakes flushers once the queue is idle.
+func (q *asyncQueue) done(err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err != nil {
+		q.err = err
+	}
+	q.pending--
+	if q.pending == 0 && q.idle != nil {
+		close(q.idle)
+		q.idle = nil
+	}
+}
+
+// flush waits until every queued message has been posted or ctx is done.
+func (q *asyncQueue) flush(ctx context.Context) error {
+	q.mu.Lock()
+	if q.pending == 0 {
+		q.mu.Unlock()
+		return nil
+	}
+	if q.idle == nil {
+		q.idle = make(chan struct{})
+	}
+	idle := q.idle
+	q.mu.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close stops accepting messages and waits for the workers to drain the queue.
+func (q *asyncQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+	q.mu.Unlock()
+	q.wg.Wait()
+	if q.stop != nil {
+		q.stopOnce.Do(func() { close(q.stop) })
+		<-q.reported
+	}
+}
+
+// setErr records err as the most recent delivery error.
+func (q *asyncQueue) setErr(err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.err = err
+}
+
+// lastErr returns the most recent delivery error.
+func (q *asyncQueue) lastErr() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.err
+}
+
+// droppedTotals returns the number of messages dropped per level since the queue started.
+func (q *asyncQueue) droppedTotals() map[LogLevel]int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	totals := make(map[LogLevel]int)
+	for level, count := range q.total {
+		if count > 0 {
+			totals[LogLevel(level)] = count
+		}
+	}
+	return totals
+}
+
+// StartAsync switches the default logger to asynchronous delivery.
+func StartAsync(opts AsyncOptions) {
+	std.StartAsync(opts)
+}
+
+// StartAsync switches the Logger to asynchronous delivery.
+// Log calls return as soon as the message is queued; delivery errors are
+// reported by Err. Call Flush or Close before the program exits.
+func (l *Logger) StartAsync(opts AsyncOptions) {
+	old := l.Writer.queue
+	l.Writer.queue = newAsyncQueue(opts)
+	if l.Writer.batch != nil {
+		l.Writer.batch.retarget(l.Writer.queue)
+	}
+	if old != nil {
+		old.close()
+	}
+}
+
+// Flush waits for the default logger's queued messages to be posted.
+func Flush(ctx context.Context) error {
+	return std.Flush(ctx)
+}
+
+// Flush waits until all queued messages have been posted or ctx is done.
+// It returns immediately for a synchronous Logger.
+func (l *Logger) Flush(ctx context.Context) error {
+	if l.Writer.batch != nil {
+		l.Writer.batch.flush()
+	}
+	if l.Writer.queue == nil {
+		return nil
+	}
+	return l.Writer.queue.flush(ctx)
+}
+
+// Close flushes and stops the default logger's async queue.
+func Close() error {
+	return std.Close()
+}
+
+// Close stops accepting messages, waits for queued messages to be posted and
+// returns the last delivery error. Logging after Close fails with ErrClosed.
+func (l *Logger) Close() error {
+	if l.Writer.batch != nil {
+		l.Writer.batch.close()
+	}
+	if l.Writer.queue == nil {
+		return nil
+	}
+	l.Writer.queue.close()
+	return l.Writer.queue.lastErr()
+}
+
+// Dropped returns how many messages the Logger's async queue has dropped per level.
+func (l *Logger) Dropped() map[LogLevel]int {
+	if l.Writer.queue == nil {
+		return map[LogLevel]int{}
+	}
+	return l.Writer.queue.droppedTotals()
+}
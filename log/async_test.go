@@ -0,0 +1,291 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newBlockingServer creates an httptest server whose handlers wait until release is closed.
+func newBlockingServer(t *testing.T) (*httptest.Server, chan struct{}) {
+	t.Helper()
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	return srv, release
+}
+
+func TestAsyncDelivers(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.StartAsync(AsyncOptions{})
+	defer logger.Close()
+	for i := range 10 {
+		logger.Infof("message %d", i)
+	}
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+	msgs := getMessages()
+	if len(msgs) != 10 {
+		t.Fatalf("expected 10 messages, got %d", len(msgs))
+	}
+	for i, msg := range msgs {
+		if want := fmt.Sprintf("INFO: message %d", i); msg != want {
+			t.Errorf("message %d out of order: got %q, want %q", i, msg, want)
+		}
+	}
+}
+
+func TestAsyncDoesNotBlock(t *testing.T) {
+	srv, release := newBlockingServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.StartAsync(AsyncOptions{QueueSize: 4})
+	start := time.Now()
+	logger.Info("queued")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Info blocked for %v", elapsed)
+	}
+	close(release)
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+}
+
+func TestAsyncFlushContext(t *testing.T) {
+	srv, release := newBlockingServer(t)
+	defer srv.Close()
+	defer close(release)
+
+	logger := New(srv.URL)
+	logger.StartAsync(AsyncOptions{})
+	logger.Info("stuck")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := logger.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestAsyncErr(t *testing.T) {
+	logger := New("http://127.0.0.1:1")
+	logger.StartAsync(AsyncOptions{})
+	logger.Info("should fail")
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+	if logger.Err() == nil {
+		t.Fatal("expected delivery error from bad webhook URL")
+	}
+	logger.Close()
+}
+
+func TestAsyncClose(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.StartAsync(AsyncOptions{Workers: 2})
+	logger.Info("before close")
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if msgs := getMessages(); len(msgs) != 1 {
+		t.Fatalf("expected Close to drain 1 message, got %d", len(msgs))
+	}
+	logger.Info("after close")
+	if !errors.Is(logger.Err(), ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", logger.Err())
+	}
+}
+
+func TestWithWriterKeepsQueue(t *testing.T) {
+	logger := New("")
+	logger.StartAsync(AsyncOptions{})
+	defer logger.Close()
+	updated := logger.WithWriter(LogWriter{Level: LevelInfo})
+	if updated.Writer.queue == nil {
+		t.Fatal("expected WithWriter to keep the async queue")
+	}
+}
+
+func TestFatalFlushesQueue(t *testing.T) {
+	if url := os.Getenv("LOG_SLACK_FATAL_WEBHOOK"); url != "" {
+		std = New(url)
+		StartAsync(AsyncOptions{})
+		Fatal("last words")
+		return
+	}
+
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatalFlushesQueue$")
+	cmd.Env = append(os.Environ(), "LOG_SLACK_FATAL_WEBHOOK="+srv.URL)
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		t.Fatalf("expected exit status 1, got %v", err)
+	}
+	msgs := getMessages()
+	if len(msgs) != 1 || msgs[0] != "ERRO: last words\n" {
+		t.Fatalf("expected the fatal message to be delivered, got %q", msgs)
+	}
+}
+
+// newGateServer creates an httptest server that records received messages,
+// signals each arrival on the returned channel and holds every response until
+// release is closed.
+func newGateServer(t *testing.T) (*httptest.Server, <-chan struct{}, chan struct{}, func() []string) {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		messages []string
+	)
+	received := make(chan struct{}, 100)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg slackMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+		mu.Lock()
+		messages = append(messages, msg.Text)
+		mu.Unlock()
+		received <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	get := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(messages)
+	}
+	return srv, received, release, get
+}
+
+func TestAsyncOverflow(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  OverflowPolicy
+		log     func(*Logger)
+		want    []string
+		dropped map[LogLevel]int
+	}{
+		{
+			name:    "DropNewest",
+			policy:  OverflowDropNewest,
+			log:     func(l *Logger) { l.Info("m1"); l.Info("m2") },
+			want:    []string{"INFO: m0", "INFO: m1"},
+			dropped: map[LogLevel]int{LevelInfo: 1},
+		},
+		{
+			name:    "DropOldest",
+			policy:  OverflowDropOldest,
+			log:     func(l *Logger) { l.Info("m1"); l.Info("m2") },
+			want:    []string{"INFO: m0", "INFO: m2"},
+			dropped: map[LogLevel]int{LevelInfo: 1},
+		},
+		{
+			name:    "KeepErrors",
+			policy:  OverflowKeepErrors,
+			log:     func(l *Logger) { l.Info("m1"); l.Info("m2"); l.Errorf("e1") },
+			want:    []string{"INFO: m0", "ERRO: e1"},
+			dropped: map[LogLevel]int{LevelInfo: 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv, received, release, getMessages := newGateServer(t)
+			defer srv.Close()
+
+			logger := New(srv.URL)
+			logger.StartAsync(AsyncOptions{QueueSize: 1, Overflow: test.policy, DropReportInterval: time.Hour})
+			logger.Info("m0")
+			<-received
+			test.log(logger)
+			close(release)
+			if err := logger.Flush(context.Background()); err != nil {
+				t.Fatalf("Flush returned error: %v", err)
+			}
+			if msgs := getMessages(); !slices.Equal(msgs, test.want) {
+				t.Errorf("expected %q, got %q", test.want, msgs)
+			}
+			if dropped := logger.Dropped(); !maps.Equal(dropped, test.dropped) {
+				t.Errorf("expected dropped %v, got %v", test.dropped, dropped)
+			}
+
+			if err := logger.Close(); err != nil {
+				t.Fatalf("Close returned error: %v", err)
+			}
+			msgs := getMessages()
+			if len(msgs) != len(test.want)+1 || !strings.Contains(msgs[len(msgs)-1], "messages dropped (INFO: ") {
+				t.Errorf("expected a drop report on Close, got %q", msgs)
+			}
+		})
+	}
+}
+
+func TestAsyncDropReportInterval(t *testing.T) {
+	srv, received, release, getMessages := newGateServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.StartAsync(AsyncOptions{QueueSize: 1, Overflow: OverflowDropNewest, DropReportInterval: 10 * time.Millisecond})
+	defer logger.Close()
+	logger.Info("m0")
+	<-received
+	logger.Info("m1")
+	logger.Info("m2")
+	logger.Info("m3")
+	close(release)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, msg := range getMessages() {
+			if strings.HasPrefix(msg, "WARN: ") && strings.Contains(msg, "messages dropped (INFO: ") {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("no drop report received, got %q", getMessages())
+}
+
+func TestAsyncDropReportFormatted(t *testing.T) {
+	srv, received, release, getMessages := newGateServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Formatter = MrkdwnFormatter{}
+	logger.StartAsync(AsyncOptions{QueueSize: 1, Overflow: OverflowDropNewest, DropReportInterval: time.Hour})
+	logger.Info("m0")
+	<-received
+	logger.Info("m1")
+	logger.Info("m2")
+	close(release)
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	msgs := getMessages()
+	if want := "*WARNING* 1 messages dropped (INFO: 1)"; msgs[len(msgs)-1] != want {
+		t.Errorf("expected drop report %q, got %q", want, msgs)
+	}
+}
@@ -0,0 +1,81 @@
+package log
+
+import "strings"
+
+// shortFieldLen is the longest field value rendered side by side with another.
+const shortFieldLen = 40
+
+// defaultColors are the attachment color bars used when neither AttachmentFormatter.Colors
+// nor LogWriter.Colors has an entry.
+var defaultColors = [...]string{
+	LevelError:   "#E01E5A",
+	LevelWarning: "#ECB22E",
+	LevelInfo:    "#2EB67D",
+	LevelDebug:   "#9E9E9E",
+	LevelTrace:   "#9E9E9E",
+}
+
+// Attachment is a legacy Slack message attachment.
+type Attachment struct {
+	Fallback string            `json:"fallback"`
+	Color    string            `json:"color"`
+	Title    string            `json:"title"`
+	Text     string            `json:"text,omitempty"`
+	Fields   []AttachmentField `json:"fields,omitempty"`
+	Footer   string            `json:"footer,omitempty"`
+	TS       int64             `json:"ts"`
+	MrkdwnIn []string          `json:"mrkdwn_in,omitempty"`
+}
+
+// AttachmentField is a title/value pair shown in an attachment's table.
+type AttachmentField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// AttachmentFormatter renders records as a single legacy attachment whose color
+// bar marks the level. The title holds the prefix and level, the footer the
+// hostname and caller.
+type AttachmentFormatter struct {
+	// Colors overrides the color bar per level, as a hex code like "#439FE0"
+	// or one of "good", "warning" and "danger". It takes precedence over
+	// LogWriter.Colors.
+	Colors map[LogLevel]string
+
+	// writerColors are the LogWriter's Colors, used for levels Colors doesn't set.
+	writerColors map[LogLevel]string
+}
+
+// color returns the color bar for level.
+func (af AttachmentFormatter) color(level LogLevel) string {
+	if color, ok := af.Colors[level]; ok {
+		return color
+	}
+	if color, ok := af.writerColors[level]; ok {
+		return color
+	}
+	return defaultColors[level]
+}
+
+// Format implements Formatter.
+func (af AttachmentFormatter) Format(r Record) (Payload, error) {
+	fallback, _ := TextFormatter{}.Format(r)
+	footer := hostname()
+	if r.Caller != "" {
+		footer = strings.TrimPrefix(footer+" | "+r.Caller, " | ")
+	}
+	a := Attachment{
+		Fallback: fallback.Text,
+		Color:    af.color(r.Level),
+		Title:    r.Prefix + levelNames[r.Level],
+		Text:     strings.TrimSuffix(r.Message, "\n"),
+		Footer:   footer,
+		TS:       r.Time.Unix(),
+		MrkdwnIn: []string{"text"},
+	}
+	for _, f := range r.Fields {
+		a.Fields = append(a.Fields, AttachmentField{Title: f.Key, Value: f.Value, Short: len(f.Value) <= shortFieldLen})
+	}
+	return Payload{Attachments: []Attachment{a}}, nil
+}
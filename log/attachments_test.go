@@ -0,0 +1,84 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAttachmentFormatter(t *testing.T) {
+	srv, getPayloads := newPayloadServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.SetPrefix("[APP] ")
+	logger.Writer.Formatter = AttachmentFormatter{}
+	before := time.Now().Unix()
+	logger.Warningw("disk almost full", "mount", "/var", "free", "2%")
+
+	payloads := getPayloads()
+	if len(payloads) != 1 {
+		t.Fatalf("expected 1 payload, got %d", len(payloads))
+	}
+	attachments, _ := payloads[0]["attachments"].([]any)
+	if len(attachments) != 1 {
+		t.Fatalf("expected 1 attachment, got %v", payloads[0])
+	}
+	a := attachments[0].(map[string]any)
+	checks := map[string]any{
+		"color":    "#ECB22E",
+		"title":    "[APP] WARNING",
+		"text":     "disk almost full",
+		"fallback": "[APP] WARN: disk almost full mount=/var free=2%",
+		"footer":   hostname() + " | log/attachments_test.go:16",
+	}
+	for key, want := range checks {
+		if a[key] != want {
+			t.Errorf("expected %s %q, got %q", key, want, a[key])
+		}
+	}
+	if ts, _ := a["ts"].(float64); int64(ts) < before {
+		t.Errorf("unexpected ts: %v", a["ts"])
+	}
+	fields, _ := a["fields"].([]any)
+	if len(fields) != 2 {
+		t.Fatalf("expected 2 fields, got %v", a["fields"])
+	}
+	first := fields[0].(map[string]any)
+	if first["title"] != "mount" || first["value"] != "/var" || first["short"] != true {
+		t.Errorf("unexpected field: %v", first)
+	}
+}
+
+func TestAttachmentColors(t *testing.T) {
+	tests := []struct {
+		level LogLevel
+		want  string
+	}{
+		{level: LevelError, want: "#E01E5A"},
+		{level: LevelWarning, want: "#ECB22E"},
+		{level: LevelInfo, want: "good"},
+		{level: LevelDebug, want: "warning"},
+		{level: LevelTrace, want: "#9E9E9E"},
+	}
+	lw := LogWriter{
+		Formatter: AttachmentFormatter{Colors: map[LogLevel]string{LevelInfo: "good"}},
+		Colors:    map[LogLevel]string{LevelInfo: "danger", LevelDebug: "warning"},
+	}
+	for _, test := range tests {
+		p, _ := lw.format(Record{Level: test.level, Time: time.Now()})
+		if got := p.Attachments[0].Color; got != test.want {
+			t.Errorf("level %d: expected color %q, got %q", test.level, test.want, got)
+		}
+	}
+}
+
+func TestAttachmentLongFieldNotShort(t *testing.T) {
+	p, _ := AttachmentFormatter{}.Format(Record{
+		Level:  LevelInfo,
+		Time:   time.Now(),
+		Fields: parseFields([]any{"query", "SELECT * FROM orders WHERE customer_id = 42 AND status = 'open'"}),
+	})
+	if p.Attachments[0].Fields[0].Short {
+		t.Error("expected a long field value not to be rendered short")
+	}
+}
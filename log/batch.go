@@ -0,0 +1,172 @@
+package log
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// BatchOptions configures how bursts of messages are coalesced into single Slack posts.
+type BatchOptions struct {
+	// Window is how long the first message of a batch waits for company. Defaults to one second.
+	Window time.Duration
+	// MaxMessages posts a batch as soon as it holds this many messages. Defaults to 50.
+	MaxMessages int
+}
+
+// batch holds the messages collected for one webhook and identity.
+type batch struct {
+	jobs  []job
+	size  int
+	timer *time.Timer
+}
+
+// batchKey identifies the messages that can be joined: those posted to the
+// same webhook with the same identity.
+type batchKey struct {
+	webhook  string
+	identity Identity
+}
+
+// batcher groups messages per webhook and identity and hands each batch to an
+// async queue as one job.
+type batcher struct {
+	mu      sync.Mutex
+	opts    BatchOptions
+	queue   *asyncQueue
+	pending map[batchKey]*batch
+	closed  bool
+}
+
+func newBatcher(opts BatchOptions, queue *asyncQueue) *batcher {
+	if opts.Window <= 0 {
+		opts.Window = time.Second
+	}
+	if opts.MaxMessages <= 0 {
+		opts.MaxMessages = 50
+	}
+	return &batcher{opts: opts, queue: queue, pending: make(map[batchKey]*batch)}
+}
+
+// add appends j to the batch for its webhook and identity, posting the batch once it is full.
+// Messages that are not plain text or belong to a thread cannot be joined;
+// they are queued on their own right after the pending batch.
+func (b *batcher) add(j job) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	key := batchKey{webhook: j.webhook, identity: j.payload.Identity}
+	if !j.payload.plain() || threadFrom(j.ctx) != nil {
+		if err := b.emit(key); err != nil {
+			return err
+		}
+		return b.queue.enqueue(j)
+	}
+	line := strings.TrimSuffix(j.payload.Text, "\n")
+	pending := b.pending[key]
+	if pending != nil && pending.size+1+len(line) > j.lw.maxText() {
+		if err := b.emit(key); err != nil {
+			return err
+		}
+		pending = nil
+	}
+	if pending == nil {
+		pending = &batch{size: -1}
+		b.pending[key] = pending
+		pending.timer = time.AfterFunc(b.opts.Window, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if b.pending[key] == pending {
+				b.emit(key)
+			}
+		})
+	}
+	j.payload.Text = line
+	pending.jobs = append(pending.jobs, j)
+	pending.size += 1 + len(line)
+	if len(pending.jobs) >= b.opts.MaxMessages {
+		return b.emit(key)
+	}
+	return nil
+}
+
+// emit joins the pending messages for key into one job and queues it.
+// Lines keep their own prefix and level tag; the batch takes the level of its
+// most severe message. The caller must hold b.mu.
+func (b *batcher) emit(key batchKey) error {
+	pending := b.pending[key]
+	if pending == nil {
+		return nil
+	}
+	delete(b.pending, key)
+	pending.timer.Stop()
+
+	lines := make([]string, len(pending.jobs))
+	combined := pending.jobs[0]
+	combined.groups = nil
+	for i, j := range pending.jobs {
+		lines[i] = j.payload.Text
+		combined.groups = append(combined.groups, j.groups...)
+		combined.level = min(combined.level, j.level)
+	}
+	combined.payload.Text = strings.Join(lines, "\n")
+	return b.queue.enqueue(combined)
+}
+
+// emitAll queues every pending batch. The caller must hold b.mu.
+func (b *batcher) emitAll() error {
+	var err error
+	for key := range b.pending {
+		if e := b.emit(key); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// flush queues every pending batch immediately.
+func (b *batcher) flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.emitAll()
+}
+
+// retarget flushes pending batches into the current queue and sends later ones to q.
+func (b *batcher) retarget(q *asyncQueue) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.emitAll()
+	b.queue = q
+}
+
+// close flushes pending batches and rejects further messages.
+func (b *batcher) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.emitAll()
+	b.closed = true
+}
+
+// StartBatching enables batching on the default logger.
+func StartBatching(opts BatchOptions) {
+	std.StartBatching(opts)
+}
+
+// StartBatching coalesces messages bound for the same webhook, and posted with
+// the same identity, into a single post.
+// A batch is posted when its window elapses, it reaches opts.MaxMessages, or
+// the next message would push it past Slack's text limit. Messages keep their
+// order and level tags, one per line.
+// Batching delivers through the async queue, so one with a single worker is
+// started if the Logger is not async yet.
+func (l *Logger) StartBatching(opts BatchOptions) {
+	if l.Writer.queue == nil {
+		l.StartAsync(AsyncOptions{})
+	}
+	if l.Writer.batch != nil {
+		l.Writer.batch.flush()
+	}
+	l.Writer.batch = newBatcher(opts, l.Writer.queue)
+}
@@ -0,0 +1,149 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBatchingCoalesces(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.SetPrefix("[APP] ")
+	logger.StartBatching(BatchOptions{Window: time.Hour})
+	defer logger.Close()
+	for i := range 5 {
+		logger.Errorf("line %d", i)
+	}
+	logger.Warningln("careful")
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+	msgs := getMessages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 batched message, got %d: %q", len(msgs), msgs)
+	}
+	want := "[APP] ERRO: line 0\n[APP] ERRO: line 1\n[APP] ERRO: line 2\n[APP] ERRO: line 3\n[APP] ERRO: line 4\n[APP] WARN: careful"
+	if msgs[0] != want {
+		t.Errorf("unexpected batch:\n%s\nwant:\n%s", msgs[0], want)
+	}
+}
+
+func TestBatchingMaxMessages(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.StartBatching(BatchOptions{Window: time.Hour, MaxMessages: 2})
+	defer logger.Close()
+	for i := range 5 {
+		logger.Infof("line %d", i)
+	}
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+	msgs := getMessages()
+	want := []string{"INFO: line 0\nINFO: line 1", "INFO: line 2\nINFO: line 3", "INFO: line 4"}
+	if strings.Join(msgs, "|") != strings.Join(want, "|") {
+		t.Errorf("expected %q, got %q", want, msgs)
+	}
+}
+
+func TestBatchingPerWebhook(t *testing.T) {
+	errSrv, getErrors := newTestServer(t)
+	defer errSrv.Close()
+	infoSrv, getInfos := newTestServer(t)
+	defer infoSrv.Close()
+
+	logger := New("")
+	logger.WithWriter(LogWriter{Error: errSrv.URL, Log: infoSrv.URL, Level: LevelInfo})
+	logger.StartBatching(BatchOptions{Window: time.Hour})
+	defer logger.Close()
+	logger.Errorf("e1")
+	logger.Info("i1")
+	logger.Errorf("e2")
+	logger.Info("i2")
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+	if msgs := getErrors(); len(msgs) != 1 || msgs[0] != "ERRO: e1\nERRO: e2" {
+		t.Errorf("unexpected error webhook messages: %q", msgs)
+	}
+	if msgs := getInfos(); len(msgs) != 1 || msgs[0] != "INFO: i1\nINFO: i2" {
+		t.Errorf("unexpected info webhook messages: %q", msgs)
+	}
+}
+
+func TestBatchingWindow(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.StartBatching(BatchOptions{Window: 10 * time.Millisecond})
+	defer logger.Close()
+	logger.Info("a")
+	logger.Info("b")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if msgs := getMessages(); len(msgs) == 1 {
+			if msgs[0] != "INFO: a\nINFO: b" {
+				t.Fatalf("unexpected batch: %q", msgs[0])
+			}
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("batch was not posted after its window elapsed")
+}
+
+func TestBatchingTextLimit(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.StartBatching(BatchOptions{Window: time.Hour})
+	defer logger.Close()
+	big := strings.Repeat("x", maxTextLen/2)
+	for i := range 3 {
+		logger.Infof("%d%s", i, big)
+	}
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+	msgs := getMessages()
+	if len(msgs) != 3 {
+		t.Fatalf("expected 3 posts, got %d", len(msgs))
+	}
+	for i, msg := range msgs {
+		if len(msg) > maxTextLen {
+			t.Errorf("post %d exceeds the text limit: %d bytes", i, len(msg))
+		}
+		if !strings.HasPrefix(msg, fmt.Sprintf("INFO: %d", i)) {
+			t.Errorf("post %d out of order: %.10q", i, msg)
+		}
+	}
+}
+
+func TestBatchingClose(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.StartBatching(BatchOptions{Window: time.Hour})
+	logger.Info("pending")
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if msgs := getMessages(); len(msgs) != 1 {
+		t.Fatalf("expected Close to post the pending batch, got %q", msgs)
+	}
+	logger.Info("after close")
+	if logger.Err() != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", logger.Err())
+	}
+}
@@ -0,0 +1,123 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"unicode/utf8"
+)
+
+// maxSectionText is the longest text Slack accepts in a section block.
+const maxSectionText = 3000
+
+// maxSectionFields is the most fields Slack accepts in a single section block.
+const maxSectionFields = 10
+
+// maxFieldText is the longest text Slack accepts in a section field.
+const maxFieldText = 2000
+
+// levelNames are the full level names shown in Block Kit messages.
+var levelNames = [...]string{
+	LevelError:   "ERROR",
+	LevelWarning: "WARNING",
+	LevelInfo:    "INFO",
+	LevelDebug:   "DEBUG",
+	LevelTrace:   "TRACE",
+}
+
+// levelEmoji are the emoji shown next to the level in Block Kit messages.
+var levelEmoji = [...]string{
+	LevelError:   ":rotating_light:",
+	LevelWarning: ":warning:",
+	LevelInfo:    ":information_source:",
+	LevelDebug:   ":beetle:",
+	LevelTrace:   ":mag:",
+}
+
+// hostname is looked up once and shown in the context of every Block Kit message.
+var hostname = sync.OnceValue(func() string {
+	h, _ := os.Hostname()
+	return h
+})
+
+// textObject is a Block Kit text composition object.
+type textObject struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func mrkdwn(text string) textObject {
+	return textObject{Type: "mrkdwn", Text: text}
+}
+
+// sectionBlock is a Block Kit section holding text or up to ten fields.
+type sectionBlock struct {
+	Type   string       `json:"type"`
+	Text   *textObject  `json:"text,omitempty"`
+	Fields []textObject `json:"fields,omitempty"`
+}
+
+// contextBlock is a Block Kit context line of small text elements.
+type contextBlock struct {
+	Type     string       `json:"type"`
+	Elements []textObject `json:"elements"`
+}
+
+// BlockFormatter renders records as Block Kit: a section with the message, a
+// context line with level, time, hostname, caller and prefix, and sections
+// holding the fields. The TextFormatter rendering is kept as the notification
+// fallback.
+type BlockFormatter struct{}
+
+// Format implements Formatter.
+func (BlockFormatter) Format(r Record) (Payload, error) {
+	p, _ := TextFormatter{}.Format(r)
+	if r.Message != "" {
+		text := mrkdwn(truncate(r.Message, maxSectionText))
+		p.Blocks = append(p.Blocks, sectionBlock{Type: "section", Text: &text})
+	}
+
+	elements := []textObject{
+		mrkdwn(fmt.Sprintf("%s *%s*", levelEmoji[r.Level], levelNames[r.Level])),
+		mrkdwn(fmt.Sprintf("<!date^%d^{date_short_pretty} {time_secs}|%s>", r.Time.Unix(), r.Time.UTC().Format("2006-01-02 15:04:05 UTC"))),
+	}
+	if h := hostname(); h != "" {
+		elements = append(elements, mrkdwn(h))
+	}
+	if r.Caller != "" {
+		elements = append(elements, mrkdwn(r.Caller))
+	}
+	if r.Prefix != "" {
+		elements = append(elements, mrkdwn(r.Prefix))
+	}
+	p.Blocks = append(p.Blocks, contextBlock{Type: "context", Elements: elements})
+
+	for i := 0; i < len(r.Fields); i += maxSectionFields {
+		section := sectionBlock{Type: "section"}
+		for _, f := range r.Fields[i:min(i+maxSectionFields, len(r.Fields))] {
+			section.Fields = append(section.Fields, mrkdwn(truncate(fmt.Sprintf("*%s*\n%s", f.Key, f.Value), maxFieldText)))
+		}
+		p.Blocks = append(p.Blocks, section)
+	}
+	return p, nil
+}
+
+// truncate shortens s to at most n bytes, ending it with an ellipsis if anything was cut
+// and there is room for one. It never splits a UTF-8 sequence.
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if len(s) <= n {
+		return s
+	}
+	const ellipsis = "…"
+	cut, mark := n-len(ellipsis), ellipsis
+	if cut < 0 {
+		cut, mark = n, ""
+	}
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + mark
+}
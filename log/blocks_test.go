@@ -0,0 +1,169 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// newPayloadServer creates an httptest server that records the full JSON payloads it receives.
+func newPayloadServer(t *testing.T) (*httptest.Server, func() []map[string]any) {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		payloads []map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading request body: %v", err)
+		}
+		var p map[string]any
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Errorf("unmarshaling payload: %v", err)
+		}
+		mu.Lock()
+		payloads = append(payloads, p)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	get := func() []map[string]any {
+		mu.Lock()
+		defer mu.Unlock()
+		cp := make([]map[string]any, len(payloads))
+		copy(cp, payloads)
+		return cp
+	}
+	return srv, get
+}
+
+// blockTexts flattens the text of every block in a decoded payload, one string per block.
+func blockTexts(p map[string]any) []string {
+	var texts []string
+	blocks, _ := p["blocks"].([]any)
+	for _, b := range blocks {
+		block := b.(map[string]any)
+		var parts []string
+		if text, ok := block["text"].(map[string]any); ok {
+			parts = append(parts, text["text"].(string))
+		}
+		for _, key := range []string{"fields", "elements"} {
+			items, _ := block[key].([]any)
+			for _, item := range items {
+				parts = append(parts, item.(map[string]any)["text"].(string))
+			}
+		}
+		texts = append(texts, block["type"].(string)+": "+strings.Join(parts, " | "))
+	}
+	return texts
+}
+
+func TestBlockFormatter(t *testing.T) {
+	srv, getPayloads := newPayloadServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.SetPrefix("[APP] ")
+	logger.Writer.Formatter = BlockFormatter{}
+	logger.Errorw("deploy failed", "service", "api", "attempt", 2)
+
+	payloads := getPayloads()
+	if len(payloads) != 1 {
+		t.Fatalf("expected 1 payload, got %d", len(payloads))
+	}
+	p := payloads[0]
+	if p["text"] != "[APP] ERRO: deploy failed service=api attempt=2" {
+		t.Errorf("unexpected fallback text: %q", p["text"])
+	}
+	texts := blockTexts(p)
+	if len(texts) != 3 {
+		t.Fatalf("expected 3 blocks, got %q", texts)
+	}
+	if texts[0] != "section: deploy failed" {
+		t.Errorf("unexpected message block: %q", texts[0])
+	}
+	if !strings.HasPrefix(texts[1], "context: :rotating_light: *ERROR* | <!date^") || !strings.HasSuffix(texts[1], "| log/blocks_test.go:76 | [APP] ") {
+		t.Errorf("unexpected context block: %q", texts[1])
+	}
+	if texts[2] != "section: *service*\napi | *attempt*\n2" {
+		t.Errorf("unexpected fields block: %q", texts[2])
+	}
+}
+
+func TestBlocksFieldSections(t *testing.T) {
+	var kv []any
+	for i := range 12 {
+		kv = append(kv, fmt.Sprintf("k%d", i), i)
+	}
+	p, _ := BlockFormatter{}.Format(Record{Level: LevelInfo, Message: "many fields", Time: time.Now(), Fields: parseFields(kv)})
+	if len(p.Blocks) != 4 {
+		t.Fatalf("expected message, context and 2 field sections, got %d blocks", len(p.Blocks))
+	}
+	if n := len(p.Blocks[2].(sectionBlock).Fields); n != maxSectionFields {
+		t.Errorf("expected %d fields in the first section, got %d", maxSectionFields, n)
+	}
+	if n := len(p.Blocks[3].(sectionBlock).Fields); n != 2 {
+		t.Errorf("expected 2 fields in the second section, got %d", n)
+	}
+}
+
+func TestBlocksLongMessage(t *testing.T) {
+	p, _ := BlockFormatter{}.Format(Record{Level: LevelInfo, Message: strings.Repeat("x", 2*maxSectionText), Time: time.Now()})
+	text := p.Blocks[0].(sectionBlock).Text.Text
+	if len(text) > maxSectionText {
+		t.Errorf("section text exceeds Slack's limit: %d bytes", len(text))
+	}
+}
+
+func TestBlocksNotBatched(t *testing.T) {
+	srv, getPayloads := newPayloadServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Formatter = BlockFormatter{}
+	logger.StartBatching(BatchOptions{Window: time.Hour})
+	defer logger.Close()
+	logger.Info("one")
+	logger.Info("two")
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+	if payloads := getPayloads(); len(payloads) != 2 {
+		t.Fatalf("expected Block Kit messages to be posted separately, got %d", len(payloads))
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{in: "short", n: 10, want: "short"},
+		{in: "exactly10!", n: 10, want: "exactly10!"},
+		{in: "hello world", n: 8, want: "hello…"},
+		{in: "ééééé", n: 7, want: "éé…"},
+		{in: "hello", n: 3, want: "…"},
+		{in: "hello", n: 2, want: "he"},
+		{in: "ééééé", n: 1, want: ""},
+		{in: "hello", n: 0, want: ""},
+		{in: "hello", n: -1, want: ""},
+	}
+	for _, test := range tests {
+		got := truncate(test.in, test.n)
+		if got != test.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", test.in, test.n, got, test.want)
+		}
+		if !utf8.ValidString(got) || len(got) > max(test.n, 0) {
+			t.Errorf("truncate(%q, %d) = %q is invalid or too long", test.in, test.n, got)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting Slack while a destination's circuit is open.
+var ErrCircuitOpen = errors.New("log: circuit open")
+
+// CircuitState is the state of a destination's circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets every post through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails posts fast with ErrCircuitOpen until the cooldown ends.
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe through to test the destination.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// CircuitBreaker stops posting to a destination that keeps failing, so a
+// revoked webhook doesn't add a failing HTTP request to every log call.
+// Breakers are tracked per destination and shared by every Logger in the
+// process. The zero value disables the breaker.
+type CircuitBreaker struct {
+	// Threshold is the number of consecutive failed attempts that opens the
+	// circuit. Only transport errors, 5xx responses and errors saying the
+	// webhook, channel or token is gone count; rejected messages and rate
+	// limiting don't.
+	Threshold int
+	// Cooldown is how long the circuit stays open before a probe is let through.
+	// Defaults to 30 seconds.
+	Cooldown time.Duration
+	// OnStateChange, if set, is called whenever a destination's circuit changes
+	// state. The destination is masked like in WebhookError.
+	OnStateChange func(dest string, from, to CircuitState)
+}
+
+// breaker is the circuit state of a single destination.
+type breaker struct {
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// breakers holds the process-wide circuit breakers keyed by destination.
+var breakers = struct {
+	sync.Mutex
+	m map[string]*breaker
+}{m: make(map[string]*breaker)}
+
+// breakerFor returns the shared breaker for dest, creating it on first use.
+func breakerFor(dest string) *breaker {
+	breakers.Lock()
+	defer breakers.Unlock()
+	b, ok := breakers.m[dest]
+	if !ok {
+		b = &breaker{}
+		breakers.m[dest] = b
+	}
+	return b
+}
+
+// call runs fn for dest unless its circuit is open, and records the outcome.
+// If ctx is done by then, the caller gave up and nothing is recorded; only a
+// timeout of the request itself counts against the destination.
+func (cb CircuitBreaker) call(ctx context.Context, dest string, fn func() error) error {
+	if cb.Threshold <= 0 {
+		return fn()
+	}
+	b := breakerFor(dest)
+	allowed, from, to := b.allow(cb, time.Now())
+	cb.notify(dest, from, to)
+	if !allowed {
+		return fmt.Errorf("%w: %s", ErrCircuitOpen, maskDestination(dest))
+	}
+	err := fn()
+	if ctx.Err() != nil {
+		b.abandon()
+		return err
+	}
+	from, to = b.record(cb, err, time.Now())
+	cb.notify(dest, from, to)
+	return err
+}
+
+// notify reports a state change, if there was one.
+func (cb CircuitBreaker) notify(dest string, from, to CircuitState) {
+	if from != to && cb.OnStateChange != nil {
+		cb.OnStateChange(maskDestination(dest), from, to)
+	}
+}
+
+// cooldown returns how long an open circuit waits before probing.
+func (cb CircuitBreaker) cooldown() time.Duration {
+	if cb.Cooldown > 0 {
+		return cb.Cooldown
+	}
+	return 30 * time.Second
+}
+
+// allow reports whether an attempt may go ahead at now. Once the cooldown has
+// passed, an open circuit turns half-open and lets a single probe through.
+func (b *breaker) allow(cb CircuitBreaker, now time.Time) (allowed bool, from, to CircuitState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	from = b.state
+	if b.state == CircuitOpen && now.Sub(b.openedAt) >= cb.cooldown() {
+		b.state = CircuitHalfOpen
+	}
+	switch b.state {
+	case CircuitOpen:
+		return false, from, b.state
+	case CircuitHalfOpen:
+		if b.probing {
+			return false, from, b.state
+		}
+		b.probing = true
+	}
+	return true, from, b.state
+}
+
+// deadEndpointCodes are the Web API {"ok": false} error strings that mean the
+// destination itself is unusable rather than the message.
+var deadEndpointCodes = []string{
+	"channel_not_found", "is_archived", "channel_is_archived", "not_in_channel",
+	"invalid_auth", "not_authed", "account_inactive", "token_revoked", "token_expired",
+	"no_permission", "missing_scope", "team_access_not_granted",
+	"internal_error", "fatal_error", "service_unavailable", "request_timeout",
+}
+
+// endpointFailure reports whether err means the destination is down or gone:
+// a transport error, a 5xx, or a revoked webhook, channel or token. Errors
+// about a single message, such as invalid_payload or 413, and rate limiting
+// come from a healthy destination and don't count.
+func endpointFailure(err error) bool {
+	var werr *WebhookError
+	if errors.As(err, &werr) {
+		return deadStatus(werr.StatusCode)
+	}
+	var aerr *APIError
+	if errors.As(err, &aerr) {
+		if aerr.StatusCode == http.StatusOK {
+			return slices.Contains(deadEndpointCodes, aerr.Code)
+		}
+		return deadStatus(aerr.StatusCode)
+	}
+	return true
+}
+
+// deadStatus reports whether an HTTP status means the destination is down or gone.
+func deadStatus(code int) bool {
+	switch code {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusGone:
+		return true
+	}
+	return code >= 500
+}
+
+// abandon ends an attempt without recording its outcome, letting another probe through.
+func (b *breaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// record updates the circuit with the outcome of an attempt made at now.
+// Cancellation says nothing about the destination and is ignored; errors that
+// are not endpoint failures count as the destination answering.
+func (b *breaker) record(cb CircuitBreaker, err error, now time.Time) (from, to CircuitState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	from = b.state
+	probe := b.probing
+	b.probing = false
+	switch {
+	case errors.Is(err, context.Canceled) || errors.Is(err, ErrNoToken):
+		return from, b.state
+	case err == nil || !endpointFailure(err):
+		b.failures = 0
+		b.state = CircuitClosed
+	default:
+		b.failures++
+		if probe || b.failures >= cb.Threshold {
+			b.state = CircuitOpen
+			b.openedAt = now
+		}
+	}
+	return from, b.state
+}
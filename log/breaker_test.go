@@ -0,0 +1,230 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBreakerOpens(t *testing.T) {
+	srv, requests := newScriptedServer(t, "", http.StatusGone, http.StatusGone, http.StatusGone, http.StatusGone)
+	defer srv.Close()
+
+	var (
+		mu      sync.Mutex
+		changes []string
+	)
+	logger := New(srv.URL)
+	logger.Writer.Breaker = CircuitBreaker{
+		Threshold: 2,
+		Cooldown:  time.Hour,
+		OnStateChange: func(dest string, from, to CircuitState) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, fmt.Sprintf("%s %s->%s", dest, from, to))
+		},
+	}
+	for range 5 {
+		logger.Info("revoked")
+	}
+
+	if got := requests(); got != 2 {
+		t.Errorf("expected posts to stop after 2 failures, got %d requests", got)
+	}
+	if !errors.Is(logger.Err(), ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", logger.Err())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if want := maskWebhook(srv.URL) + " closed->open"; len(changes) != 1 || changes[0] != want {
+		t.Errorf("expected %q, got %q", want, changes)
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	cb := CircuitBreaker{Threshold: 1, Cooldown: time.Minute}
+	b := &breaker{}
+	now := time.Now()
+	boom := errors.New("boom")
+
+	if allowed, _, _ := b.allow(cb, now); !allowed {
+		t.Fatal("expected a closed circuit to allow posts")
+	}
+	if _, to := b.record(cb, boom, now); to != CircuitOpen {
+		t.Fatalf("expected the circuit to open, got %s", to)
+	}
+	if allowed, _, _ := b.allow(cb, now.Add(time.Second)); allowed {
+		t.Error("expected an open circuit to fail fast")
+	}
+
+	later := now.Add(time.Minute)
+	allowed, from, to := b.allow(cb, later)
+	if !allowed || from != CircuitOpen || to != CircuitHalfOpen {
+		t.Fatalf("expected a probe after the cooldown, got %v %s->%s", allowed, from, to)
+	}
+	if allowed, _, _ := b.allow(cb, later); allowed {
+		t.Error("expected only one probe at a time")
+	}
+	if _, to := b.record(cb, boom, later); to != CircuitOpen {
+		t.Fatalf("expected a failed probe to reopen the circuit, got %s", to)
+	}
+
+	later = later.Add(time.Minute)
+	b.allow(cb, later)
+	if from, to := b.record(cb, nil, later); from != CircuitHalfOpen || to != CircuitClosed {
+		t.Errorf("expected a successful probe to close the circuit, got %s->%s", from, to)
+	}
+}
+
+func TestBreakerRecovers(t *testing.T) {
+	srv, requests := newScriptedServer(t, "", http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Breaker = CircuitBreaker{Threshold: 1, Cooldown: 20 * time.Millisecond}
+	logger.Info("fails and opens")
+	logger.Info("fails fast")
+	time.Sleep(30 * time.Millisecond)
+	logger.Info("probe succeeds")
+	logger.Info("closed again")
+
+	if got := requests(); got != 3 {
+		t.Errorf("expected 3 requests, got %d", got)
+	}
+}
+
+func TestBreakerNotRetried(t *testing.T) {
+	srv, requests := newScriptedServer(t, "", http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Retry = RetryPolicy{MaxAttempts: 5, BaseBackoff: time.Millisecond}
+	logger.Writer.Breaker = CircuitBreaker{Threshold: 2, Cooldown: time.Hour}
+	logger.Info("gives up once the circuit opens")
+
+	if got := requests(); got != 2 {
+		t.Errorf("expected retries to stop at the open circuit, got %d requests", got)
+	}
+}
+
+func TestBreakerFallback(t *testing.T) {
+	srv, _ := newScriptedServer(t, "", http.StatusNotFound)
+	defer srv.Close()
+	var sink bytes.Buffer
+
+	logger := New(srv.URL)
+	logger.Writer.Breaker = CircuitBreaker{Threshold: 1, Cooldown: time.Hour}
+	logger.Writer.Fallbacks = map[LogLevel][]Fallback{LevelInfo: {{Writer: &sink}}}
+	logger.Info("one")
+	logger.Info("two")
+
+	var ferr *FallbackError
+	if !errors.As(logger.Err(), &ferr) || !errors.Is(logger.Err(), ErrCircuitOpen) {
+		t.Errorf("expected the open circuit to spool to the fallback, got %v", logger.Err())
+	}
+	if got := bytes.Count(sink.Bytes(), []byte("\n")); got != 2 {
+		t.Errorf("expected both messages in the sink, got %q", sink.String())
+	}
+}
+
+func TestBreakerIgnoresMessageErrors(t *testing.T) {
+	srv, requests := newScriptedServer(t, "", http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusTooManyRequests)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Breaker = CircuitBreaker{Threshold: 2, Cooldown: time.Hour}
+	logger.Info("malformed")
+	logger.Info("too large")
+	logger.Info("rate limited")
+	logger.Info("good one")
+
+	if got := requests(); got != 4 {
+		t.Errorf("expected every message to be posted, got %d requests", got)
+	}
+	if errors.Is(logger.Err(), ErrCircuitOpen) {
+		t.Errorf("expected the circuit to stay closed, got %v", logger.Err())
+	}
+}
+
+func TestEndpointFailure(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: errors.New("connection refused"), want: true},
+		{err: &WebhookError{StatusCode: http.StatusGone}, want: true},
+		{err: &WebhookError{StatusCode: http.StatusNotFound, Code: "no_service"}, want: true},
+		{err: &WebhookError{StatusCode: http.StatusBadGateway}, want: true},
+		{err: &WebhookError{StatusCode: http.StatusBadRequest, Code: "invalid_payload"}, want: false},
+		{err: &WebhookError{StatusCode: http.StatusTooManyRequests}, want: false},
+		{err: &APIError{StatusCode: http.StatusOK, Code: "channel_not_found"}, want: true},
+		{err: &APIError{StatusCode: http.StatusOK, Code: "invalid_auth"}, want: true},
+		{err: &APIError{StatusCode: http.StatusOK, Code: "ratelimited"}, want: false},
+		{err: &APIError{StatusCode: http.StatusOK, Code: "msg_too_long"}, want: false},
+		{err: &APIError{StatusCode: http.StatusServiceUnavailable}, want: true},
+	}
+	for _, test := range tests {
+		if got := endpointFailure(test.err); got != test.want {
+			t.Errorf("%v: expected %v, got %v", test.err, test.want, got)
+		}
+	}
+}
+
+func TestBreakerIgnoresCallerDeadline(t *testing.T) {
+	srv, release := newBlockingServer(t)
+	defer srv.Close()
+	defer close(release)
+
+	var (
+		mu      sync.Mutex
+		changes []string
+	)
+	logger := New(srv.URL)
+	logger.Writer.RateLimit = RateLimit{Rate: 0.1}
+	logger.Writer.Breaker = CircuitBreaker{
+		Threshold: 1,
+		Cooldown:  time.Hour,
+		OnStateChange: func(dest string, from, to CircuitState) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, to.String())
+		},
+	}
+	// The first post takes the only token and hangs until the caller's
+	// deadline; the second runs out of time waiting for the rate limit.
+	for range 2 {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		logger.InfoContext(ctx, "impatient")
+		cancel()
+		if !errors.Is(logger.Err(), context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, got %v", logger.Err())
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) != 0 {
+		t.Errorf("expected the circuit to stay closed, got %q", changes)
+	}
+}
+
+func TestBreakerCountsRequestTimeout(t *testing.T) {
+	srv, release := newBlockingServer(t)
+	defer srv.Close()
+	defer close(release)
+
+	logger := New(srv.URL)
+	logger.Writer.Timeout = 10 * time.Millisecond
+	logger.Writer.Breaker = CircuitBreaker{Threshold: 1, Cooldown: time.Hour}
+	logger.Info("times out")
+	logger.Info("fails fast")
+
+	if !errors.Is(logger.Err(), ErrCircuitOpen) {
+		t.Errorf("expected a request timeout to open the circuit, got %v", logger.Err())
+	}
+}
@@ -0,0 +1,116 @@
+package log
+
+import (
+	"context"
+	"fmt"
+)
+
+// ErrorContext writes an error level message using the default logger.
+func ErrorContext(ctx context.Context, args ...any) {
+	std.ErrorContext(ctx, args...)
+}
+
+// ErrorContext writes an error level message. ctx bounds the post to Slack.
+func (l *Logger) ErrorContext(ctx context.Context, args ...any) {
+	_, err := l.Writer.output(ctx, LevelError, fmt.Appendln(nil, args...))
+	l.setErr(err)
+}
+
+// ErrorfContext writes a formatted error level message using the default logger.
+func ErrorfContext(ctx context.Context, format string, args ...any) {
+	std.ErrorfContext(ctx, format, args...)
+}
+
+// ErrorfContext writes a formatted error level message. ctx bounds the post to Slack.
+func (l *Logger) ErrorfContext(ctx context.Context, format string, args ...any) {
+	_, err := l.Writer.output(ctx, LevelError, fmt.Appendf(nil, format, args...))
+	l.setErr(err)
+}
+
+// WarningContext writes a warning level message using the default logger.
+func WarningContext(ctx context.Context, warning string) {
+	std.WarningContext(ctx, warning)
+}
+
+// WarningContext writes a warning level message. ctx bounds the post to Slack.
+func (l *Logger) WarningContext(ctx context.Context, warning string) {
+	_, err := l.Writer.output(ctx, LevelWarning, []byte(warning))
+	l.setErr(err)
+}
+
+// WarningfContext writes a formatted warning level message using the default logger.
+func WarningfContext(ctx context.Context, format string, args ...any) {
+	std.WarningfContext(ctx, format, args...)
+}
+
+// WarningfContext writes a formatted warning level message. ctx bounds the post to Slack.
+func (l *Logger) WarningfContext(ctx context.Context, format string, args ...any) {
+	_, err := l.Writer.output(ctx, LevelWarning, fmt.Appendf(nil, format, args...))
+	l.setErr(err)
+}
+
+// InfoContext writes an info level message using the default logger.
+func InfoContext(ctx context.Context, info string) {
+	std.InfoContext(ctx, info)
+}
+
+// InfoContext writes an info level message. ctx bounds the post to Slack.
+func (l *Logger) InfoContext(ctx context.Context, info string) {
+	_, err := l.Writer.output(ctx, LevelInfo, []byte(info))
+	l.setErr(err)
+}
+
+// InfofContext writes a formatted info level message using the default logger.
+func InfofContext(ctx context.Context, format string, args ...any) {
+	std.InfofContext(ctx, format, args...)
+}
+
+// InfofContext writes a formatted info level message. ctx bounds the post to Slack.
+func (l *Logger) InfofContext(ctx context.Context, format string, args ...any) {
+	_, err := l.Writer.output(ctx, LevelInfo, fmt.Appendf(nil, format, args...))
+	l.setErr(err)
+}
+
+// DebugContext writes a debug level message using the default logger.
+func DebugContext(ctx context.Context, debug string) {
+	std.DebugContext(ctx, debug)
+}
+
+// DebugContext writes a debug level message. ctx bounds the post to Slack.
+func (l *Logger) DebugContext(ctx context.Context, debug string) {
+	_, err := l.Writer.output(ctx, LevelDebug, []byte(debug))
+	l.setErr(err)
+}
+
+// DebugfContext writes a formatted debug level message using the default logger.
+func DebugfContext(ctx context.Context, format string, args ...any) {
+	std.DebugfContext(ctx, format, args...)
+}
+
+// DebugfContext writes a formatted debug level message. ctx bounds the post to Slack.
+func (l *Logger) DebugfContext(ctx context.Context, format string, args ...any) {
+	_, err := l.Writer.output(ctx, LevelDebug, fmt.Appendf(nil, format, args...))
+	l.setErr(err)
+}
+
+// TraceContext writes a trace level message using the default logger.
+func TraceContext(ctx context.Context, trace string) {
+	std.TraceContext(ctx, trace)
+}
+
+// TraceContext writes a trace level message. ctx bounds the post to Slack.
+func (l *Logger) TraceContext(ctx context.Context, trace string) {
+	_, err := l.Writer.output(ctx, LevelTrace, []byte(trace))
+	l.setErr(err)
+}
+
+// TracefContext writes a formatted trace level message using the default logger.
+func TracefContext(ctx context.Context, format string, args ...any) {
+	std.TracefContext(ctx, format, args...)
+}
+
+// TracefContext writes a formatted trace level message. ctx bounds the post to Slack.
+func (l *Logger) TracefContext(ctx context.Context, format string, args ...any) {
+	_, err := l.Writer.output(ctx, LevelTrace, fmt.Appendf(nil, format, args...))
+	l.setErr(err)
+}
@@ -0,0 +1,151 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type requestIDKey struct{}
+
+func TestContextMethods(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	ctx := context.Background()
+	logger := New(srv.URL)
+	logger.ErrorContext(ctx, "error msg")
+	logger.ErrorfContext(ctx, "errorf %s", "msg")
+	logger.WarningContext(ctx, "warning msg")
+	logger.WarningfContext(ctx, "warningf %s", "msg")
+	logger.InfoContext(ctx, "info msg")
+	logger.InfofContext(ctx, "infof %s", "msg")
+	logger.DebugContext(ctx, "debug msg")
+	logger.DebugfContext(ctx, "debugf %s", "msg")
+	logger.TraceContext(ctx, "trace msg")
+	logger.TracefContext(ctx, "tracef %s", "msg")
+
+	want := []string{
+		"ERRO: error msg\n",
+		"ERRO: errorf msg",
+		"WARN: warning msg",
+		"WARN: warningf msg",
+		"INFO: info msg",
+		"INFO: infof msg",
+		"DEBG: debug msg",
+		"DEBG: debugf msg",
+		"TRCE: trace msg",
+		"TRCE: tracef msg",
+	}
+	msgs := getMessages()
+	if strings.Join(msgs, "|") != strings.Join(want, "|") {
+		t.Errorf("expected %q, got %q", want, msgs)
+	}
+}
+
+func TestPackageLevelContextFunctions(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	oldStd := std
+	std = New(srv.URL)
+	defer func() { std = oldStd }()
+
+	ctx := context.Background()
+	ErrorContext(ctx, "error msg")
+	ErrorfContext(ctx, "errorf %s", "msg")
+	WarningContext(ctx, "warning msg")
+	WarningfContext(ctx, "warningf %s", "msg")
+	InfoContext(ctx, "info msg")
+	InfofContext(ctx, "infof %s", "msg")
+	DebugContext(ctx, "debug msg")
+	DebugfContext(ctx, "debugf %s", "msg")
+	TraceContext(ctx, "trace msg")
+	TracefContext(ctx, "tracef %s", "msg")
+
+	if msgs := getMessages(); len(msgs) != 10 {
+		t.Fatalf("expected 10 messages, got %d", len(msgs))
+	}
+}
+
+func TestContextDeadline(t *testing.T) {
+	srv, release := newBlockingServer(t)
+	defer srv.Close()
+	defer close(release)
+
+	logger := New(srv.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	logger.InfoContext(ctx, "hangs")
+	if !errors.Is(logger.Err(), context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", logger.Err())
+	}
+}
+
+func TestContextCancelStopsRetries(t *testing.T) {
+	srv, requests := newScriptedServer(t, "", http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Retry = RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	logger.ErrorContext(ctx, "gives up early")
+	if !errors.Is(logger.Err(), context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", logger.Err())
+	}
+	if got := requests(); got != 1 {
+		t.Errorf("expected 1 request, got %d", got)
+	}
+}
+
+func TestContextFields(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.ContextFields = func(ctx context.Context) []any {
+		if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+			return []any{"request_id", id}
+		}
+		return nil
+	}
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "abc123")
+	logger.InfoContext(ctx, "handled")
+	logger.Info("no context")
+
+	msgs := getMessages()
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(msgs))
+	}
+	if msgs[0] != "INFO: handled request_id=abc123" {
+		t.Errorf("unexpected message: %q", msgs[0])
+	}
+	if msgs[1] != "INFO: no context" {
+		t.Errorf("unexpected message: %q", msgs[1])
+	}
+}
+
+func TestAsyncIgnoresCancellation(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.StartAsync(AsyncOptions{})
+	defer logger.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	logger.InfoContext(ctx, "outlives the request")
+	cancel()
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+	if msgs := getMessages(); len(msgs) != 1 {
+		t.Fatalf("expected the queued message to be delivered, got %q", msgs)
+	}
+	if err := logger.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
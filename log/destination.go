@@ -0,0 +1,142 @@
+package log
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Destination is a webhook URL or channel ID with its own delivery settings.
+type Destination struct {
+	// URL is an incoming webhook URL or a channel ID.
+	URL string
+	// Retry overrides LogWriter.Retry for this destination.
+	Retry *RetryPolicy
+}
+
+// DestinationError reports that a message could not be posted to one destination.
+type DestinationError struct {
+	// Destination is the webhook URL, with its secret masked, or the channel ID.
+	Destination string
+	Err         error
+}
+
+func (e *DestinationError) Error() string {
+	return e.Destination + ": " + e.Err.Error()
+}
+
+func (e *DestinationError) Unwrap() error {
+	return e.Err
+}
+
+// FanOutError is returned when a message posted to several destinations
+// failed for some of them. The others received it.
+type FanOutError struct {
+	// Total is the number of destinations the message was posted to.
+	Total  int
+	Errors []*DestinationError
+}
+
+func (e *FanOutError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("slack: %d of %d destinations failed: %s", len(e.Errors), e.Total, strings.Join(msgs, "; "))
+}
+
+func (e *FanOutError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Failed returns the destinations that failed.
+func (e *FanOutError) Failed() []string {
+	dests := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		dests[i] = err.Destination
+	}
+	return dests
+}
+
+// maskDestination hides the secret of a webhook URL; channel IDs are shown as is.
+func maskDestination(dest string) string {
+	if isChannel(dest) {
+		return dest
+	}
+	return maskWebhook(dest)
+}
+
+// fanOut calls post for every destination with the writer configured for it.
+// A single destination's error is returned as is; failures among several are
+// reported as a *FanOutError. On an async writer, delivery failures among
+// several destinations are collected per message and reported by the queue as
+// a *FanOutError once the message has been posted everywhere.
+func (lw LogWriter) fanOut(dests []Destination, post func(lw LogWriter, dest string) error) error {
+	if lw.queue != nil && len(dests) > 1 {
+		g := &fanOutGroup{total: len(dests), pending: 1}
+		lw.fanout = g
+		defer func() {
+			if err := g.done("", nil); err != nil {
+				lw.queue.setErr(err)
+			}
+		}()
+	}
+	var errs []*DestinationError
+	for _, d := range dests {
+		dlw := lw
+		if d.Retry != nil {
+			dlw.Retry = *d.Retry
+		}
+		if err := post(dlw, d.URL); err != nil {
+			errs = append(errs, &DestinationError{Destination: maskDestination(d.URL), Err: err})
+		}
+	}
+	switch {
+	case len(errs) == 0:
+		return nil
+	case len(dests) == 1:
+		return errs[0].Err
+	default:
+		return &FanOutError{Total: len(dests), Errors: errs}
+	}
+}
+
+// fanOutGroup collects the delivery errors of a message queued for several
+// destinations. Every queued job of the message holds a reference, as does
+// fanOut until it has queued them all.
+type fanOutGroup struct {
+	mu      sync.Mutex
+	total   int
+	pending int
+	errs    []*DestinationError
+}
+
+// add takes a reference for a queued job.
+func (g *fanOutGroup) add() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pending++
+}
+
+// done releases a reference, recording err as dest's failure. The last one to
+// be released returns the message's *FanOutError, if any destination failed.
+func (g *fanOutGroup) done(dest string, err error) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err != nil {
+		dest = maskDestination(dest)
+		if !slices.ContainsFunc(g.errs, func(e *DestinationError) bool { return e.Destination == dest }) {
+			g.errs = append(g.errs, &DestinationError{Destination: dest, Err: err})
+		}
+	}
+	g.pending--
+	if g.pending > 0 || len(g.errs) == 0 {
+		return nil
+	}
+	return &FanOutError{Total: g.total, Errors: g.errs}
+}
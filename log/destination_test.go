@@ -0,0 +1,147 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDestinationsFanOut(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+	f := newFakeSlack(t)
+	defer f.Close()
+
+	logger := newAPILogger(f, srv.URL)
+	logger.Writer.Destinations = map[LogLevel][]Destination{
+		LevelError: {{URL: "C0INCIDENTS"}, {URL: srv.URL}},
+	}
+	logger.Error("payments down")
+	logger.Info("all good")
+	if err := logger.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if msgs := getMessages(); len(msgs) != 2 {
+		t.Errorf("expected the team webhook to get each message once, got %q", msgs)
+	}
+	if calls := f.Calls(); len(calls) != 1 || calls[0].Body["channel"] != "C0INCIDENTS" {
+		t.Errorf("expected the error in the incident channel, got %+v", calls)
+	}
+}
+
+func TestDestinationsIndependentRetries(t *testing.T) {
+	flaky, flakyRequests := newScriptedServer(t, "", http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	defer flaky.Close()
+	steady, steadyRequests := newScriptedServer(t, "", http.StatusServiceUnavailable)
+	defer steady.Close()
+
+	logger := New(steady.URL)
+	logger.Writer.Destinations = map[LogLevel][]Destination{
+		LevelInfo: {{URL: flaky.URL, Retry: &RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond}}},
+	}
+	logger.Info("hello")
+
+	if got := flakyRequests(); got != 3 {
+		t.Errorf("expected the flaky destination to be retried, got %d requests", got)
+	}
+	if got := steadyRequests(); got != 1 {
+		t.Errorf("expected the primary to use the writer's policy, got %d requests", got)
+	}
+	var ferr *FanOutError
+	if !errors.As(logger.Err(), &ferr) {
+		t.Fatalf("expected a *FanOutError, got %v", logger.Err())
+	}
+	if ferr.Total != 2 || len(ferr.Errors) != 1 || ferr.Failed()[0] != maskWebhook(steady.URL) {
+		t.Errorf("unexpected failures: %v", ferr)
+	}
+	var werr *WebhookError
+	if !errors.As(logger.Err(), &werr) || werr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected the underlying *WebhookError, got %v", logger.Err())
+	}
+}
+
+func TestFanOutError(t *testing.T) {
+	err := &FanOutError{Total: 3, Errors: []*DestinationError{
+		{Destination: "C0123", Err: &APIError{Method: "chat.postMessage", Code: "not_in_channel"}},
+		{Destination: "https://hooks.slack.com/services/***", Err: errors.New("timeout")},
+	}}
+	want := "slack: 2 of 3 destinations failed: C0123: slack api chat.postMessage: not_in_channel; https://hooks.slack.com/services/***: timeout"
+	if err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+	if !strings.Contains(strings.Join(err.Failed(), ","), "C0123") {
+		t.Errorf("unexpected failed destinations: %q", err.Failed())
+	}
+}
+
+func TestDestinationSingleErrorUnwrapped(t *testing.T) {
+	srv, _ := newScriptedServer(t, "", http.StatusNotFound)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Info("lost")
+	if _, ok := logger.Err().(*WebhookError); !ok {
+		t.Errorf("expected a single destination's error as is, got %T", logger.Err())
+	}
+}
+
+func TestDestinationsAsyncFanOutError(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+	gone, _ := newScriptedServer(t, "", http.StatusGone)
+	defer gone.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Destinations = map[LogLevel][]Destination{
+		LevelError: {{URL: gone.URL + "/T000/B000/secret"}},
+	}
+	logger.StartAsync(AsyncOptions{Workers: 2})
+	logger.Error("payments down")
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+	if msgs := getMessages(); len(msgs) != 1 {
+		t.Errorf("expected the healthy destination to get the message, got %q", msgs)
+	}
+	var ferr *FanOutError
+	if !errors.As(logger.Err(), &ferr) {
+		t.Fatalf("expected a *FanOutError, got %T: %v", logger.Err(), logger.Err())
+	}
+	if ferr.Total != 2 || len(ferr.Errors) != 1 || ferr.Failed()[0] != gone.URL+"/T000/***" {
+		t.Errorf("unexpected fan-out error: %v", ferr)
+	}
+	var werr *WebhookError
+	if !errors.As(ferr, &werr) || werr.StatusCode != http.StatusGone {
+		t.Errorf("expected the 410 *WebhookError inside, got %v", ferr)
+	}
+	if err := logger.Close(); err == nil {
+		t.Error("expected Close to report the fan-out error")
+	}
+}
+
+func TestDestinationsBatchedFanOutError(t *testing.T) {
+	srv, _ := newTestServer(t)
+	defer srv.Close()
+	gone, _ := newScriptedServer(t, "", http.StatusGone, http.StatusGone)
+	defer gone.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Destinations = map[LogLevel][]Destination{
+		LevelInfo: {{URL: gone.URL}},
+	}
+	logger.StartBatching(BatchOptions{Window: time.Hour})
+	defer logger.Close()
+	logger.Info("one")
+	logger.Info("two")
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+	var ferr *FanOutError
+	if !errors.As(logger.Err(), &ferr) || ferr.Total != 2 || len(ferr.Errors) != 1 {
+		t.Fatalf("expected a *FanOutError with one failed destination, got %v", logger.Err())
+	}
+}
@@ -0,0 +1,98 @@
+package log
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Fallback is where a message goes when its destination can't be reached:
+// another webhook URL or channel ID, or a local sink such as os.Stderr or a file.
+type Fallback struct {
+	// URL is a webhook URL or channel ID. It is ignored if Writer is set.
+	URL string
+	// Writer receives the message as a line of text.
+	Writer io.Writer
+}
+
+// name identifies f in errors.
+func (f Fallback) name() string {
+	if f.Writer != nil {
+		return fmt.Sprintf("writer %T", f.Writer)
+	}
+	return maskDestination(f.URL)
+}
+
+// FallbackError is returned when a message could not be posted to its
+// destination but was delivered to a fallback.
+type FallbackError struct {
+	// Fallback is the fallback that received the message.
+	Fallback string
+	// Err is why the destination failed.
+	Err error
+}
+
+func (e *FallbackError) Error() string {
+	return fmt.Sprintf("%v (delivered to fallback %s)", e.Err, e.Fallback)
+}
+
+func (e *FallbackError) Unwrap() error {
+	return e.Err
+}
+
+// fallback tries the level's fallbacks in order after posting p to webhook
+// failed with cause. Each message is annotated with why the primary failed.
+// If every fallback fails too, cause is returned along with their errors.
+func (lw LogWriter) fallback(ctx context.Context, level LogLevel, webhook string, p Payload, cause error) error {
+	note := fmt.Sprintf("[fallback: %s failed: %v]", maskDestination(webhook), cause)
+	errs := []error{cause}
+	for _, f := range lw.Fallbacks[level] {
+		var err error
+		if f.Writer != nil {
+			err = writeFallback(f.Writer, note, p)
+		} else {
+			_, err = lw.deliver(ctx, f.URL, annotate(p, escapeMrkdwn(note, false), isChannel(f.URL)))
+		}
+		if err == nil {
+			return &FallbackError{Fallback: f.name(), Err: cause}
+		}
+		errs = append(errs, &DestinationError{Destination: f.name(), Err: err})
+	}
+	return errors.Join(errs...)
+}
+
+// annotate prepends note to p for posting to a fallback. Uploads are inlined
+// unless the fallback is a channel, and thread replies are posted on their own.
+func annotate(p Payload, note string, channel bool) Payload {
+	p.ThreadTS, p.ReplyBroadcast = "", false
+	if p.file != nil && !channel {
+		p = inlineData(p, p.file.data)
+		p.file = nil
+	}
+	if len(p.Blocks) > 0 {
+		p.Blocks = append([]any{contextBlock{Type: "context", Elements: []textObject{mrkdwn(note)}}}, p.Blocks...)
+	}
+	p.Text = note + "\n" + p.Text
+	return p
+}
+
+// writeFallback writes p's text to w as a single annotated line, with Slack's
+// escaping undone. Payloads without text, such as attachments, are written as
+// their fallback text.
+func writeFallback(w io.Writer, note string, p Payload) error {
+	text := p.Text
+	for _, a := range p.Attachments {
+		if text != "" {
+			break
+		}
+		text = cmp.Or(a.Fallback, a.Text)
+	}
+	text = mrkdwnUnescaper.Replace(text)
+	if p.file != nil {
+		text += "\n" + string(p.file.data)
+	}
+	_, err := fmt.Fprintf(w, "%s %s\n", note, text)
+	return err
+}
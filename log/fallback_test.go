@@ -0,0 +1,170 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFallbackWebhook(t *testing.T) {
+	primary, primaryRequests := newScriptedServer(t, "", http.StatusGone, http.StatusGone)
+	defer primary.Close()
+	backup, getMessages := newTestServer(t)
+	defer backup.Close()
+
+	logger := New(primary.URL)
+	logger.Writer.Retry = RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond}
+	logger.Writer.Fallbacks = map[LogLevel][]Fallback{LevelError: {{URL: backup.URL}}}
+	logger.Error("payments down")
+
+	if got := primaryRequests(); got != 1 {
+		t.Errorf("expected the primary to be tried first, got %d requests", got)
+	}
+	msgs := getMessages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected the backup to receive the message, got %q", msgs)
+	}
+	wantNote := "[fallback: " + maskWebhook(primary.URL) + " failed: slack webhook " + maskWebhook(primary.URL) + ": 410 Gone]\n"
+	if msgs[0] != wantNote+"ERRO: payments down\n" {
+		t.Errorf("unexpected fallback message: %q", msgs[0])
+	}
+	var ferr *FallbackError
+	if !errors.As(logger.Err(), &ferr) || ferr.Fallback != maskWebhook(backup.URL) {
+		t.Fatalf("expected a *FallbackError, got %v", logger.Err())
+	}
+	var werr *WebhookError
+	if !errors.As(logger.Err(), &werr) || werr.StatusCode != http.StatusGone {
+		t.Errorf("expected the primary's *WebhookError, got %v", logger.Err())
+	}
+}
+
+func TestFallbackOrder(t *testing.T) {
+	primary, _ := newScriptedServer(t, "", http.StatusNotFound)
+	defer primary.Close()
+	broken, brokenRequests := newScriptedServer(t, "", http.StatusNotFound)
+	defer broken.Close()
+	var sink bytes.Buffer
+
+	logger := New(primary.URL)
+	logger.Writer.Fallbacks = map[LogLevel][]Fallback{
+		LevelInfo: {{URL: broken.URL}, {Writer: &sink}},
+	}
+	logger.Infow("order placed", "id", 7)
+
+	if got := brokenRequests(); got != 1 {
+		t.Errorf("expected the first fallback to be tried, got %d requests", got)
+	}
+	line := sink.String()
+	if !strings.HasPrefix(line, "[fallback: "+maskWebhook(primary.URL)+" failed: ") || !strings.HasSuffix(line, "] INFO: order placed id=7\n") {
+		t.Errorf("unexpected sink line: %q", line)
+	}
+	var ferr *FallbackError
+	if !errors.As(logger.Err(), &ferr) || ferr.Fallback != "writer *bytes.Buffer" {
+		t.Errorf("expected delivery to the writer, got %v", logger.Err())
+	}
+}
+
+func TestFallbackAllFail(t *testing.T) {
+	primary, _ := newScriptedServer(t, "", http.StatusNotFound)
+	defer primary.Close()
+	backup, _ := newScriptedServer(t, "", http.StatusForbidden)
+	defer backup.Close()
+
+	logger := New(primary.URL)
+	logger.Writer.Fallbacks = map[LogLevel][]Fallback{LevelInfo: {{URL: backup.URL}}}
+	logger.Info("lost")
+
+	err := logger.Err()
+	var ferr *FallbackError
+	if err == nil || errors.As(err, &ferr) {
+		t.Fatalf("expected a plain failure, got %v", err)
+	}
+	var derr *DestinationError
+	if !errors.As(err, &derr) || derr.Destination != maskWebhook(backup.URL) {
+		t.Errorf("expected the fallback's failure to be reported, got %v", err)
+	}
+}
+
+func TestFallbackOtherLevels(t *testing.T) {
+	primary, _ := newScriptedServer(t, "", http.StatusNotFound)
+	defer primary.Close()
+	var sink bytes.Buffer
+
+	logger := New(primary.URL)
+	logger.Writer.Fallbacks = map[LogLevel][]Fallback{LevelError: {{Writer: &sink}}}
+	logger.Info("no fallback for info")
+	if sink.Len() != 0 {
+		t.Errorf("expected no fallback for info, got %q", sink.String())
+	}
+}
+
+func TestFallbackAsync(t *testing.T) {
+	primary, _ := newScriptedServer(t, "", http.StatusNotFound)
+	defer primary.Close()
+	var sink bytes.Buffer
+
+	logger := New(primary.URL)
+	logger.Writer.Fallbacks = map[LogLevel][]Fallback{LevelWarning: {{Writer: &sink}}}
+	logger.StartAsync(AsyncOptions{})
+	logger.Warning("queued")
+	logger.Close()
+	if !strings.HasSuffix(sink.String(), "] WARN: queued\n") {
+		t.Errorf("expected the queued message in the sink, got %q", sink.String())
+	}
+}
+
+func TestAnnotateBlocks(t *testing.T) {
+	p, _ := BlockFormatter{}.Format(Record{Level: LevelError, Message: "down", Time: time.Now()})
+	a := annotate(p, "[fallback: x failed]", false)
+	if len(a.Blocks) != len(p.Blocks)+1 {
+		t.Fatalf("expected a note block, got %d blocks", len(a.Blocks))
+	}
+	if note := a.Blocks[0].(contextBlock).Elements[0].Text; note != "[fallback: x failed]" {
+		t.Errorf("unexpected note block: %q", note)
+	}
+}
+
+func TestAnnotateInlinesUploadInBlocks(t *testing.T) {
+	p, _ := BlockFormatter{}.Format(Record{Level: LevelError, Message: "down", Time: time.Now()})
+	p.file = &fileUpload{name: "dump.txt", data: []byte("stack")}
+	a := annotate(p, "[fallback: x failed]", false)
+	if a.file != nil {
+		t.Fatal("expected the upload to be inlined")
+	}
+	last := a.Blocks[len(a.Blocks)-1].(sectionBlock)
+	if last.Text == nil || last.Text.Text != "```\nstack\n```" {
+		t.Errorf("expected the data in a section block, got %+v", last)
+	}
+}
+
+func TestFallbackWriterAttachment(t *testing.T) {
+	primary, _ := newScriptedServer(t, "", http.StatusNotFound)
+	defer primary.Close()
+
+	var buf bytes.Buffer
+	logger := New(primary.URL)
+	logger.Writer.Formatter = AttachmentFormatter{}
+	logger.Writer.Fallbacks = map[LogLevel][]Fallback{LevelInfo: {{Writer: &buf}}}
+	logger.Info("database on fire")
+
+	if got := buf.String(); !strings.HasSuffix(got, "404 Not Found] INFO: database on fire\n") {
+		t.Errorf("expected the attachment's text in the sink, got %q", got)
+	}
+}
+
+func TestFallbackWriterUnescaped(t *testing.T) {
+	primary, _ := newScriptedServer(t, "", http.StatusNotFound)
+	defer primary.Close()
+
+	var buf bytes.Buffer
+	logger := New(primary.URL)
+	logger.Writer.Fallbacks = map[LogLevel][]Fallback{LevelError: {{Writer: &buf}}}
+	logger.Errorf("a < b && c > d")
+
+	if got := buf.String(); !strings.HasSuffix(got, "] ERRO: a < b && c > d\n") {
+		t.Errorf("expected the plain text in the sink, got %q", got)
+	}
+}
@@ -0,0 +1,119 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// parseFields pairs up kv into fields.
+// A key that is not a string, or a value without a key, is kept under "!BADKEY".
+func parseFields(kv []any) []Field {
+	var fields []Field
+	for len(kv) > 0 {
+		key, val := "!BADKEY", kv[0]
+		if s, ok := kv[0].(string); ok && len(kv) > 1 {
+			key, val = s, kv[1]
+			kv = kv[2:]
+		} else {
+			kv = kv[1:]
+		}
+		fields = append(fields, Field{Key: key, Value: fmt.Sprint(val)})
+	}
+	return fields
+}
+
+// appendFields renders fields onto msg as a trailing key=value list.
+func appendFields(msg string, fields []Field) string {
+	if len(fields) == 0 {
+		return msg
+	}
+	var b strings.Builder
+	b.WriteString(strings.TrimSuffix(msg, "\n"))
+	for _, f := range fields {
+		b.WriteByte(' ')
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		b.WriteString(quoteValue(f.Value))
+	}
+	return b.String()
+}
+
+// quoteValue quotes v if it would otherwise be ambiguous in a key=value list.
+func quoteValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " =\"\t\n") {
+		return strconv.Quote(v)
+	}
+	return v
+}
+
+// With returns a child of the default logger that renders kv on every message.
+func With(kv ...any) *Logger {
+	return std.With(kv...)
+}
+
+// With returns a child Logger that renders the key/value pairs kv after every
+// message, e.g. With("service", "api") appends "service=api". The child shares
+// the parent's webhooks and async queue but tracks its own error.
+func (l *Logger) With(kv ...any) *Logger {
+	child := &Logger{Writer: l.Writer}
+	child.Writer.fields = append(slices.Clip(l.Writer.fields), kv...)
+	return child
+}
+
+// Errorw writes an error level message followed by key/value pairs using the default logger.
+func Errorw(msg string, kv ...any) {
+	std.Errorw(msg, kv...)
+}
+
+// Errorw writes an error level message followed by key/value pairs.
+func (l *Logger) Errorw(msg string, kv ...any) {
+	_, err := l.Writer.output(context.Background(), LevelError, []byte(msg), kv...)
+	l.setErr(err)
+}
+
+// Warningw writes a warning level message followed by key/value pairs using the default logger.
+func Warningw(msg string, kv ...any) {
+	std.Warningw(msg, kv...)
+}
+
+// Warningw writes a warning level message followed by key/value pairs.
+func (l *Logger) Warningw(msg string, kv ...any) {
+	_, err := l.Writer.output(context.Background(), LevelWarning, []byte(msg), kv...)
+	l.setErr(err)
+}
+
+// Infow writes an info level message followed by key/value pairs using the default logger.
+func Infow(msg string, kv ...any) {
+	std.Infow(msg, kv...)
+}
+
+// Infow writes an info level message followed by key/value pairs.
+func (l *Logger) Infow(msg string, kv ...any) {
+	_, err := l.Writer.output(context.Background(), LevelInfo, []byte(msg), kv...)
+	l.setErr(err)
+}
+
+// Debugw writes a debug level message followed by key/value pairs using the default logger.
+func Debugw(msg string, kv ...any) {
+	std.Debugw(msg, kv...)
+}
+
+// Debugw writes a debug level message followed by key/value pairs.
+func (l *Logger) Debugw(msg string, kv ...any) {
+	_, err := l.Writer.output(context.Background(), LevelDebug, []byte(msg), kv...)
+	l.setErr(err)
+}
+
+// Tracew writes a trace level message followed by key/value pairs using the default logger.
+func Tracew(msg string, kv ...any) {
+	std.Tracew(msg, kv...)
+}
+
+// Tracew writes a trace level message followed by key/value pairs.
+func (l *Logger) Tracew(msg string, kv ...any) {
+	_, err := l.Writer.output(context.Background(), LevelTrace, []byte(msg), kv...)
+	l.setErr(err)
+}
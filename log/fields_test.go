@@ -0,0 +1,127 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWith(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	parent := New(srv.URL)
+	child := parent.With("service", "api")
+	grandchild := child.With("user_id", 42)
+	parent.Info("parent")
+	child.Info("child")
+	grandchild.Errorf("grandchild %d", 3)
+	child.Info("child again")
+
+	want := []string{
+		"INFO: parent",
+		"INFO: child service=api",
+		"ERRO: grandchild 3 service=api user_id=42",
+		"INFO: child again service=api",
+	}
+	msgs := getMessages()
+	if strings.Join(msgs, "|") != strings.Join(want, "|") {
+		t.Errorf("expected %q, got %q", want, msgs)
+	}
+}
+
+func TestWithTracksOwnError(t *testing.T) {
+	parent := New("http://127.0.0.1:1")
+	child := parent.With("k", "v")
+	child.Info("should fail")
+	if child.Err() == nil {
+		t.Fatal("expected child error")
+	}
+	if parent.Err() != nil {
+		t.Errorf("parent should not see the child's error: %v", parent.Err())
+	}
+}
+
+func TestWMethods(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL).With("service", "api")
+	logger.Errorw("failed", "attempt", 3)
+	logger.Warningw("slow", "ms", 1200)
+	logger.Infow("started", "port", 8080)
+	logger.Debugw("cache", "hit", true)
+	logger.Tracew("raw", "bytes", "a b")
+
+	want := []string{
+		"ERRO: failed service=api attempt=3",
+		"WARN: slow service=api ms=1200",
+		"INFO: started service=api port=8080",
+		"DEBG: cache service=api hit=true",
+		`TRCE: raw service=api bytes="a b"`,
+	}
+	msgs := getMessages()
+	if strings.Join(msgs, "|") != strings.Join(want, "|") {
+		t.Errorf("expected %q, got %q", want, msgs)
+	}
+}
+
+func TestPackageLevelFields(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	oldStd := std
+	std = New(srv.URL)
+	defer func() { std = oldStd }()
+
+	With("service", "api").Info("child")
+	Errorw("error msg", "k", 1)
+	Warningw("warning msg", "k", 1)
+	Infow("info msg", "k", 1)
+	Debugw("debug msg", "k", 1)
+	Tracew("trace msg", "k", 1)
+
+	msgs := getMessages()
+	if len(msgs) != 6 {
+		t.Fatalf("expected 6 messages, got %d", len(msgs))
+	}
+	if msgs[0] != "INFO: child service=api" {
+		t.Errorf("unexpected message: %q", msgs[0])
+	}
+}
+
+func TestFieldOrder(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.ContextFields = func(ctx context.Context) []any {
+		return []any{"trace_id", "t1"}
+	}
+	logger.With("service", "api").Infow("done", "status", 200)
+	msgs := getMessages()
+	if len(msgs) != 1 || msgs[0] != "INFO: done service=api trace_id=t1 status=200" {
+		t.Errorf("unexpected messages: %q", msgs)
+	}
+}
+
+func TestAppendFields(t *testing.T) {
+	tests := []struct {
+		name string
+		kv   []any
+		want string
+	}{
+		{name: "none", kv: nil, want: "msg\n"},
+		{name: "pairs", kv: []any{"a", 1, "b", true}, want: "msg a=1 b=true"},
+		{name: "quoted", kv: []any{"user", "jane doe", "empty", ""}, want: `msg user="jane doe" empty=""`},
+		{name: "missing value", kv: []any{"a", 1, "dangling"}, want: "msg a=1 !BADKEY=dangling"},
+		{name: "bad key", kv: []any{42, "a", 1}, want: "msg !BADKEY=42 a=1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := appendFields("msg\n", parseFields(test.kv)); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package log
+
+import (
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is a single log message handed to a Formatter.
+// Message and Fields are already escaped for Slack mrkdwn.
+type Record struct {
+	Level   LogLevel
+	Message string
+	Prefix  string
+	Time    time.Time
+	// Fields holds the Logger's fields, the context fields and the call's
+	// key/value pairs, in that order.
+	Fields []Field
+	// Caller is the "dir/file.go:line" of the logging call, or empty if unknown.
+	Caller string
+}
+
+// Field is a single key/value pair attached to a Record.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Payload is the JSON body posted to Slack.
+// Blocks holds Block Kit blocks of any type that marshals to Slack's JSON.
+type Payload struct {
+	Text        string       `json:"text,omitempty"`
+	Blocks      []any        `json:"blocks,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+	// ThreadTS and ReplyBroadcast are set for replies in a thread. They are
+	// only honored by the Web API.
+	ThreadTS       string `json:"thread_ts,omitempty"`
+	ReplyBroadcast bool   `json:"reply_broadcast,omitempty"`
+	// Identity is filled in from the LogWriter's identity for the level.
+	Identity
+
+	// file is uploaded with Text as its comment instead of posting a message.
+	file *fileUpload
+}
+
+// plain reports whether p is plain text that can be joined with other messages.
+func (p Payload) plain() bool {
+	return len(p.Blocks) == 0 && len(p.Attachments) == 0 && p.file == nil
+}
+
+// Formatter renders a Record into the Payload posted to Slack.
+type Formatter interface {
+	Format(r Record) (Payload, error)
+}
+
+// TextFormatter renders records as level-tagged plain text with their fields
+// appended, e.g. "[APP] ERRO: message key=value". It is the default Formatter.
+type TextFormatter struct{}
+
+// Format implements Formatter.
+func (TextFormatter) Format(r Record) (Payload, error) {
+	return Payload{Text: r.Prefix + appendFields(levelTags[r.Level]+": "+r.Message, r.Fields)}, nil
+}
+
+// MrkdwnFormatter renders records as Slack mrkdwn: the level in bold, the
+// message, then one bulleted line per field.
+type MrkdwnFormatter struct{}
+
+// Format implements Formatter.
+func (MrkdwnFormatter) Format(r Record) (Payload, error) {
+	var b strings.Builder
+	b.WriteString(r.Prefix)
+	b.WriteString("*" + levelNames[r.Level] + "* ")
+	b.WriteString(strings.TrimSuffix(r.Message, "\n"))
+	for _, f := range r.Fields {
+		b.WriteString("\n• *" + f.Key + ":* " + f.Value)
+	}
+	return Payload{Text: b.String()}, nil
+}
+
+// pkgPath is the import path of this package, used to skip its frames when finding the caller.
+var pkgPath = reflect.TypeFor[Logger]().PkgPath()
+
+// caller returns the location of the first call outside this package and log/slog.
+func caller() string {
+	var pcs [32]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	for {
+		f, more := frames.Next()
+		internal := strings.HasPrefix(f.Function, pkgPath+".") && !strings.HasSuffix(f.File, "_test.go")
+		if !internal && !strings.HasPrefix(f.Function, "log/slog.") && f.File != "" {
+			return filepath.Base(filepath.Dir(f.File)) + "/" + filepath.Base(f.File) + ":" + strconv.Itoa(f.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package log
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// funcFormatter adapts a function to the Formatter interface.
+type funcFormatter func(r Record) (Payload, error)
+
+func (f funcFormatter) Format(r Record) (Payload, error) { return f(r) }
+
+func TestTextFormatter(t *testing.T) {
+	p, err := TextFormatter{}.Format(Record{
+		Level:   LevelWarning,
+		Message: "disk almost full",
+		Prefix:  "[APP] ",
+		Fields:  parseFields([]any{"mount", "/var"}),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "[APP] WARN: disk almost full mount=/var"; p.Text != want {
+		t.Errorf("expected %q, got %q", want, p.Text)
+	}
+}
+
+func TestMrkdwnFormatter(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.SetPrefix("[APP] ")
+	logger.Writer.Formatter = MrkdwnFormatter{}
+	logger.Errorw("deploy failed", "service", "api", "attempt", 2)
+
+	msgs := getMessages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	if want := "[APP] *ERROR* deploy failed\n• *service:* api\n• *attempt:* 2"; msgs[0] != want {
+		t.Errorf("expected %q, got %q", want, msgs[0])
+	}
+}
+
+func TestCustomFormatter(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	var got Record
+	logger := New(srv.URL)
+	logger.Writer.Formatter = funcFormatter(func(r Record) (Payload, error) {
+		got = r
+		return Payload{Text: strings.ToUpper(r.Message)}, nil
+	})
+	before := time.Now()
+	logger.Infow("custom", "k", "v")
+
+	if msgs := getMessages(); len(msgs) != 1 || msgs[0] != "CUSTOM" {
+		t.Fatalf("expected the custom rendering, got %q", msgs)
+	}
+	if got.Level != LevelInfo || got.Time.Before(before) || len(got.Fields) != 1 || got.Fields[0] != (Field{Key: "k", Value: "v"}) {
+		t.Errorf("unexpected record: %+v", got)
+	}
+}
+
+func TestFormatterError(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	boom := errors.New("boom")
+	logger := New(srv.URL)
+	logger.Writer.Formatter = funcFormatter(func(Record) (Payload, error) {
+		return Payload{}, boom
+	})
+	logger.Info("never posted")
+	if !errors.Is(logger.Err(), boom) {
+		t.Errorf("expected the formatter error, got %v", logger.Err())
+	}
+	if msgs := getMessages(); len(msgs) != 0 {
+		t.Errorf("expected nothing posted, got %q", msgs)
+	}
+}
+
+func TestCaller(t *testing.T) {
+	srv, _ := newTestServer(t)
+	defer srv.Close()
+
+	var callers []string
+	lw := New(srv.URL).Writer
+	lw.Formatter = funcFormatter(func(r Record) (Payload, error) {
+		callers = append(callers, r.Caller)
+		return Payload{Text: r.Message}, nil
+	})
+	logger := New(srv.URL).WithWriter(lw)
+	logger.Info("method")
+	logger.With("k", "v").Infow("fields")
+	slog.New(NewSlogHandler(lw)).Info("slog")
+
+	want := []string{"log/format_test.go:99", "log/format_test.go:100", "log/format_test.go:101"}
+	if strings.Join(callers, ",") != strings.Join(want, ",") {
+		t.Errorf("expected callers %q, got %q", want, callers)
+	}
+}
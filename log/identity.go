@@ -0,0 +1,35 @@
+package log
+
+// Identity overrides how messages appear in Slack. Empty fields keep the
+// webhook's or app's defaults.
+type Identity struct {
+	// Username is the name messages are posted as.
+	Username string `json:"username,omitempty"`
+	// IconEmoji is the emoji used as the avatar, e.g. ":rotating_light:".
+	IconEmoji string `json:"icon_emoji,omitempty"`
+	// IconURL is the URL of an image used as the avatar.
+	IconURL string `json:"icon_url,omitempty"`
+	// Channel redirects webhook posts, e.g. "#alerts", where the webhook type
+	// allows it. Web API destinations are already channels and ignore it.
+	Channel string `json:"channel,omitempty"`
+}
+
+// merge returns id with the non-empty fields of o applied on top.
+// An icon in o replaces both of id's icons.
+func (id Identity) merge(o Identity) Identity {
+	if o.Username != "" {
+		id.Username = o.Username
+	}
+	if o.IconEmoji != "" || o.IconURL != "" {
+		id.IconEmoji, id.IconURL = o.IconEmoji, o.IconURL
+	}
+	if o.Channel != "" {
+		id.Channel = o.Channel
+	}
+	return id
+}
+
+// identity returns the identity messages at level are posted with.
+func (lw LogWriter) identity(level LogLevel) Identity {
+	return lw.Identity.merge(lw.Identities[level])
+}
@@ -0,0 +1,99 @@
+package log
+
+import (
+	"context"
+	"maps"
+	"testing"
+	"time"
+)
+
+func TestIdentity(t *testing.T) {
+	srv, getPayloads := newPayloadServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Identity = Identity{Username: "api-prod", IconURL: "https://example.com/api.png"}
+	logger.Writer.Identities = map[LogLevel]Identity{
+		LevelError: {IconEmoji: ":rotating_light:", Channel: "#incidents"},
+		LevelDebug: {Username: "api-prod (debug)", IconEmoji: ":zzz:"},
+	}
+	logger.Error("down")
+	logger.Info("up")
+	logger.Debug("quiet")
+
+	payloads := getPayloads()
+	if len(payloads) != 3 {
+		t.Fatalf("expected 3 payloads, got %d", len(payloads))
+	}
+	tests := []map[string]any{
+		{"username": "api-prod", "icon_emoji": ":rotating_light:", "icon_url": nil, "channel": "#incidents"},
+		{"username": "api-prod", "icon_emoji": nil, "icon_url": "https://example.com/api.png", "channel": nil},
+		{"username": "api-prod (debug)", "icon_emoji": ":zzz:", "icon_url": nil, "channel": nil},
+	}
+	for i, want := range tests {
+		for key, value := range want {
+			if payloads[i][key] != value {
+				t.Errorf("payload %d: expected %s %v, got %v", i, key, value, payloads[i][key])
+			}
+		}
+	}
+}
+
+func TestIdentityWebAPI(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+
+	logger := newAPILogger(f, "C0123")
+	logger.Writer.Identity = Identity{Username: "api-prod", Channel: "#elsewhere"}
+	logger.Info("hello")
+	calls := f.Calls()
+	if len(calls) != 1 || calls[0].Body["channel"] != "C0123" || calls[0].Body["username"] != "api-prod" {
+		t.Errorf("expected the destination channel and the username, got %+v", calls)
+	}
+}
+
+func TestIdentityBatched(t *testing.T) {
+	srv, getPayloads := newPayloadServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Identities = map[LogLevel]Identity{
+		LevelError: {Username: "alerts"},
+		LevelInfo:  {Username: "chatter"},
+	}
+	logger.StartBatching(BatchOptions{Window: time.Hour})
+	defer logger.Close()
+	logger.Info("one")
+	logger.Error("two")
+	logger.Info("three")
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+	got := make(map[any]any)
+	for _, p := range getPayloads() {
+		got[p["username"]] = p["text"]
+	}
+	want := map[any]any{"chatter": "INFO: one\nINFO: three", "alerts": "ERRO: two"}
+	if !maps.Equal(got, want) {
+		t.Errorf("expected one batch per identity %q, got %q", want, got)
+	}
+}
+
+func TestIdentityKeepsPayloadTemplate(t *testing.T) {
+	srv, getPayloads := newPayloadServer(t)
+	defer srv.Close()
+
+	tf, err := ParsePayloadTemplate(`{"text": {{json .Message}}, "username": "deploy-bot"}`)
+	if err != nil {
+		t.Fatalf("ParsePayloadTemplate returned error: %v", err)
+	}
+	logger := New(srv.URL)
+	logger.Writer.Formatter = tf
+	logger.Writer.Identity = Identity{Username: "api-prod", IconEmoji: ":robot_face:"}
+	logger.Info("deployed")
+
+	payloads := getPayloads()
+	if len(payloads) != 1 || payloads[0]["username"] != "deploy-bot" || payloads[0]["icon_emoji"] != ":robot_face:" {
+		t.Errorf("expected the template's username and the writer's icon, got %v", payloads)
+	}
+}
@@ -0,0 +1,63 @@
+package log
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mrkdwnEscaper escapes the characters Slack treats as control characters in mrkdwn.
+var mrkdwnEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// mrkdwnUnescaper reverses mrkdwnEscaper, for writing messages outside Slack.
+var mrkdwnUnescaper = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">")
+
+// mentionPattern matches Slack's special mention, user, channel and user group
+// sequences, with an optional "|label".
+var mentionPattern = regexp.MustCompile(`<(?:!(?:here|channel|everyone)|!subteam\^[A-Z0-9]+|[@#][A-Z0-9]+)(?:\|[^<>]*)?>`)
+
+// escapeMrkdwn escapes s so Slack shows it literally. If allowMentions is set,
+// mention sequences such as <!here> and <@U123> are left intact.
+func escapeMrkdwn(s string, allowMentions bool) string {
+	if !allowMentions {
+		return mrkdwnEscaper.Replace(s)
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range mentionPattern.FindAllStringIndex(s, -1) {
+		b.WriteString(mrkdwnEscaper.Replace(s[last:m[0]]))
+		b.WriteString(s[m[0]:m[1]])
+		last = m[1]
+	}
+	b.WriteString(mrkdwnEscaper.Replace(s[last:]))
+	return b.String()
+}
+
+// escape applies the writer's escaping to the message and fields of r.
+func (lw LogWriter) escape(r Record) Record {
+	r.Message = escapeMrkdwn(r.Message, lw.AllowMentions)
+	fields := make([]Field, len(r.Fields))
+	for i, f := range r.Fields {
+		fields[i] = Field{Key: escapeMrkdwn(f.Key, lw.AllowMentions), Value: escapeMrkdwn(f.Value, lw.AllowMentions)}
+	}
+	r.Fields = fields
+	if lw.CodeBlock {
+		r.Message = codeBlock(r.Message)
+	}
+	return r
+}
+
+// codeBlock wraps s in a fenced code block. Runs of backticks inside s are
+// broken up with zero-width spaces so they can't close the fence early.
+func codeBlock(s string) string {
+	var b strings.Builder
+	b.WriteString("```\n")
+	s = strings.TrimSuffix(s, "\n")
+	for i := range len(s) {
+		if i > 0 && s[i] == '`' && s[i-1] == '`' {
+			b.WriteString("\u200b")
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteString("\n```")
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEscapeMrkdwn(t *testing.T) {
+	tests := []struct {
+		in            string
+		allowMentions bool
+		want          string
+	}{
+		{in: "a < b && c > d", want: "a &lt; b &amp;&amp; c &gt; d"},
+		{in: "hey <!channel> and <@U123>", want: "hey &lt;!channel&gt; and &lt;@U123&gt;"},
+		{in: "hey <!channel> and <@U123|bob>", allowMentions: true, want: "hey <!channel> and <@U123|bob>"},
+		{in: "see <#C42> & <https://evil.example|click>", allowMentions: true, want: "see <#C42> &amp; &lt;https://evil.example|click&gt;"},
+		{in: "<!subteam^S01> <!here>", allowMentions: true, want: "<!subteam^S01> <!here>"},
+	}
+	for _, test := range tests {
+		if got := escapeMrkdwn(test.in, test.allowMentions); got != test.want {
+			t.Errorf("escapeMrkdwn(%q, %v) = %q, want %q", test.in, test.allowMentions, got, test.want)
+		}
+	}
+}
+
+func TestEscapedMessage(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Infow("user said <!channel>", "input", "<script>")
+	logger.Writer.AllowMentions = true
+	logger.Infow("deploy done <!here>")
+	logger.Writer.Write([]byte("raw <!everyone>"))
+
+	want := []string{
+		`INFO: user said &lt;!channel&gt; input=&lt;script&gt;`,
+		`INFO: deploy done <!here>`,
+		`raw <!everyone>`,
+	}
+	if got := getMessages(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestCodeBlock(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.CodeBlock = true
+	logger.Errorln("panic: ```boom``` <nil>")
+
+	msgs := getMessages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	if want := "ERRO: ```\npanic: `\u200b`\u200b`boom`\u200b`\u200b` &lt;nil&gt;\n```"; msgs[0] != want {
+		t.Errorf("expected %q, got %q", want, msgs[0])
+	}
+	if strings.Count(msgs[0], "```") != 2 {
+		t.Errorf("expected exactly one fence pair, got %q", msgs[0])
+	}
+}
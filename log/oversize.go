@@ -0,0 +1,142 @@
+package log
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// OversizePolicy decides what happens to message text longer than LogWriter.MaxTextLen.
+type OversizePolicy int
+
+const (
+	// OversizeTruncate cuts the text short and ends it with "...(N bytes omitted)".
+	OversizeTruncate OversizePolicy = iota
+	// OversizeSplit posts the text as numbered continuation messages: "(1/3) ...", "(2/3) ...".
+	OversizeSplit
+)
+
+const (
+	// fence opens and closes a code block.
+	fence = "```"
+	// numberReserve is room left in each split part for its "(i/n) " number.
+	numberReserve = len("(9999/9999) ")
+	// markerReserve is room left for the omitted marker when truncating.
+	markerReserve = len("\n...(99999999999 bytes omitted)")
+	// minTextLen is the smallest MaxTextLen honored, leaving room for the markers.
+	minTextLen = 100
+)
+
+// maxText returns the longest message text the writer posts.
+func (lw LogWriter) maxText() int {
+	if lw.MaxTextLen > 0 {
+		return max(lw.MaxTextLen, minTextLen)
+	}
+	return maxTextLen
+}
+
+// fit applies the writer's oversize policy to p, returning the payloads to post in order.
+// The policy covers the text and the text of attachments. Plain text and the
+// text of a lone attachment are split; anything else is truncated. Every part
+// is a copy of p, so it keeps p's thread, identity and attachment styling.
+func (lw LogWriter) fit(p Payload) []Payload {
+	limit := lw.maxText()
+	if lw.Oversize == OversizeSplit && len(p.Blocks) == 0 && p.file == nil {
+		switch {
+		case len(p.Attachments) == 0 && len(p.Text) > limit:
+			return splitPayload(p, p.Text, limit, func(p *Payload, text string) {
+				p.Text = text
+			})
+		case len(p.Attachments) == 1 && len(p.Attachments[0].Text) > limit && len(p.Text) <= limit:
+			return splitPayload(p, p.Attachments[0].Text, limit, func(p *Payload, text string) {
+				p.Attachments = []Attachment{p.Attachments[0]}
+				p.Attachments[0].Text = text
+				p.Attachments[0].Fallback = truncateText(p.Attachments[0].Fallback, limit)
+			})
+		}
+	}
+	p.Text = truncateText(p.Text, limit)
+	if len(p.Attachments) > 0 {
+		p.Attachments = slices.Clone(p.Attachments)
+		for i, a := range p.Attachments {
+			p.Attachments[i].Text = truncateText(a.Text, limit)
+			p.Attachments[i].Fallback = truncateText(a.Fallback, limit)
+		}
+	}
+	return []Payload{p}
+}
+
+// splitPayload splits text into numbered parts and returns a copy of p for
+// each, with set putting the part in place.
+func splitPayload(p Payload, text string, limit int, set func(p *Payload, text string)) []Payload {
+	parts := splitText(text, limit-numberReserve)
+	payloads := make([]Payload, len(parts))
+	for i, part := range parts {
+		payloads[i] = p
+		set(&payloads[i], fmt.Sprintf("(%d/%d) %s", i+1, len(parts), part))
+	}
+	return payloads
+}
+
+// truncateText shortens s to at most limit bytes, closing an open code block
+// and ending with a marker saying how much was cut.
+func truncateText(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	cut := cutPoint(s, limit-markerReserve-len("\n"+fence))
+	head := s[:cut]
+	if strings.Count(head, fence)%2 == 1 {
+		head += "\n" + fence
+	}
+	return fmt.Sprintf("%s\n...(%d bytes omitted)", head, len(s)-cut)
+}
+
+// splitText breaks s into parts of at most limit bytes. A code block open at
+// a break is closed at the end of the part and reopened at the start of the next.
+func splitText(s string, limit int) []string {
+	size := limit - len(fence+"\n") - len("\n"+fence)
+	var parts []string
+	open := false
+	for s != "" {
+		cut := len(s)
+		if cut > size {
+			cut = cutPoint(s, size)
+		}
+		part := s[:cut]
+		s = s[cut:]
+		wasOpen := open
+		if strings.Count(part, fence)%2 == 1 {
+			open = !open
+		}
+		if wasOpen {
+			part = fence + "\n" + part
+		}
+		if open && s != "" {
+			part += "\n" + fence
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// cutPoint returns where to cut s so the head is at most n bytes. It prefers
+// the end of a line in the second half and never splits a UTF-8 sequence or a
+// run of backticks.
+func cutPoint(s string, n int) int {
+	if len(s) <= n {
+		return len(s)
+	}
+	if i := strings.LastIndexByte(s[:n], '\n'); i >= n/2 {
+		return i + 1
+	}
+	cut := n
+	for cut > 0 && (!utf8.RuneStart(s[cut]) || s[cut] == '`' && s[cut-1] == '`') {
+		cut--
+	}
+	if cut == 0 {
+		return n
+	}
+	return cut
+}
@@ -0,0 +1,213 @@
+package log
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestOversizeTruncate(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.MaxTextLen = 200
+	logger.Info(strings.Repeat("é", 300))
+
+	msgs := getMessages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	msg := msgs[0]
+	if len(msg) > 200 || !utf8.ValidString(msg) {
+		t.Errorf("truncated text is too long or invalid: %d bytes", len(msg))
+	}
+	head, marker, ok := strings.Cut(msg, "\n...(")
+	if !ok || marker != strconv.Itoa(6+600-len(head))+" bytes omitted)" {
+		t.Errorf("unexpected omitted marker in %q", msg)
+	}
+}
+
+func TestOversizeTruncateClosesFence(t *testing.T) {
+	text := truncateText("```\n"+strings.Repeat("line\n", 100)+"```", 200)
+	if strings.Count(text, fence) != 2 {
+		t.Errorf("expected the code block to be closed, got %q", text)
+	}
+	if !strings.HasSuffix(text, " bytes omitted)") {
+		t.Errorf("expected the marker after the code block, got %q", text)
+	}
+}
+
+func TestOversizeSplit(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.MaxTextLen = 200
+	logger.Writer.Oversize = OversizeSplit
+	logger.Writer.CodeBlock = true
+	var lines []string
+	for i := range 40 {
+		lines = append(lines, "goroutine "+strconv.Itoa(i)+" [running]:")
+	}
+	logger.Error(strings.Join(lines, "\n"))
+
+	msgs := getMessages()
+	if len(msgs) < 2 {
+		t.Fatalf("expected several messages, got %d", len(msgs))
+	}
+	var joined strings.Builder
+	for i, msg := range msgs {
+		number := "(" + strconv.Itoa(i+1) + "/" + strconv.Itoa(len(msgs)) + ") "
+		if !strings.HasPrefix(msg, number) {
+			t.Errorf("message %d lacks %q: %q", i, number, msg)
+		}
+		if len(msg) > 200 {
+			t.Errorf("message %d is %d bytes", i, len(msg))
+		}
+		if strings.Count(msg, fence) != 2 {
+			t.Errorf("message %d does not hold a whole code block: %q", i, msg)
+		}
+		joined.WriteString(msg)
+	}
+	for _, line := range lines {
+		if !strings.Contains(joined.String(), line) {
+			t.Errorf("line %q was lost or split", line)
+		}
+	}
+}
+
+func TestSplitTextUTF8(t *testing.T) {
+	s := strings.Repeat("日本語", 100)
+	parts := splitText(s, 100)
+	if strings.Join(parts, "") != s {
+		t.Error("parts do not add up to the original text")
+	}
+	for _, part := range parts {
+		if len(part) > 100 || !utf8.ValidString(part) {
+			t.Errorf("invalid part: %q", part)
+		}
+	}
+}
+
+func TestOversizeBatched(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.MaxTextLen = 200
+	logger.StartBatching(BatchOptions{Window: time.Hour})
+	defer logger.Close()
+	for range 10 {
+		logger.Info(strings.Repeat("x", 50))
+	}
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+	for _, msg := range getMessages() {
+		if len(msg) > 200 {
+			t.Errorf("batched message exceeds MaxTextLen: %d bytes", len(msg))
+		}
+	}
+}
+
+func TestOversizeAttachment(t *testing.T) {
+	srv, getPayloads := newPayloadServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Formatter = AttachmentFormatter{}
+	logger.Writer.MaxTextLen = 200
+	logger.Info(strings.Repeat("x", 5000))
+
+	payloads := getPayloads()
+	if len(payloads) != 1 {
+		t.Fatalf("expected 1 payload, got %d", len(payloads))
+	}
+	a := payloads[0]["attachments"].([]any)[0].(map[string]any)
+	for _, key := range []string{"text", "fallback"} {
+		if text := a[key].(string); len(text) > 200 || !strings.HasSuffix(text, " bytes omitted)") {
+			t.Errorf("attachment %s was not truncated: %d bytes", key, len(text))
+		}
+	}
+	if a["color"] != defaultColors[LevelInfo] {
+		t.Errorf("truncation lost the attachment color: %v", a["color"])
+	}
+}
+
+func TestOversizeSplitAttachment(t *testing.T) {
+	srv, getPayloads := newPayloadServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Formatter = AttachmentFormatter{}
+	logger.Writer.MaxTextLen = 200
+	logger.Writer.Oversize = OversizeSplit
+	logger.Warning(strings.Repeat("x", 500))
+
+	payloads := getPayloads()
+	if len(payloads) != 3 {
+		t.Fatalf("expected 3 payloads, got %d", len(payloads))
+	}
+	for i, p := range payloads {
+		a := p["attachments"].([]any)[0].(map[string]any)
+		number := "(" + strconv.Itoa(i+1) + "/3) "
+		if text := a["text"].(string); len(text) > 200 || !strings.HasPrefix(text, number) {
+			t.Errorf("part %d: unexpected attachment text %q", i, text)
+		}
+		if a["color"] != defaultColors[LevelWarning] {
+			t.Errorf("part %d: expected the warning color, got %v", i, a["color"])
+		}
+	}
+}
+
+func TestOversizeSplitThreaded(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+
+	logger := newAPILogger(f, "C0123")
+	logger.Writer.MaxTextLen = 200
+	logger.Writer.Oversize = OversizeSplit
+	logger.Writer.BroadcastErrors = true
+	logger.Error(strings.Repeat("x", 500))
+
+	calls := f.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 calls, got %d", len(calls))
+	}
+	for i, want := range []any{nil, "1700000000.000001", "1700000000.000001"} {
+		if got := calls[i].Body["thread_ts"]; got != want {
+			t.Errorf("part %d: expected thread_ts %v, got %v", i, want, got)
+		}
+		if calls[i].Body["reply_broadcast"] != nil {
+			t.Errorf("part %d: continuations should not be broadcast", i)
+		}
+	}
+}
+
+func TestOversizeSplitInThread(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+	defer EndThread("oversize")
+
+	logger := newAPILogger(f, "C0123")
+	logger.Writer.MaxTextLen = 200
+	logger.Writer.Oversize = OversizeSplit
+	logger.Writer.BroadcastErrors = true
+	job := logger.Thread("oversize")
+	job.Info("job started")
+	job.Error(strings.Repeat("x", 500))
+
+	calls := f.Calls()
+	if len(calls) != 4 {
+		t.Fatalf("expected 4 calls, got %d", len(calls))
+	}
+	for i, call := range calls[1:] {
+		if call.Body["thread_ts"] != "1700000000.000001" || call.Body["reply_broadcast"] != true {
+			t.Errorf("part %d: expected a broadcast reply, got thread_ts %v, reply_broadcast %v", i, call.Body["thread_ts"], call.Body["reply_broadcast"])
+		}
+	}
+}
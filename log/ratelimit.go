@@ -0,0 +1,92 @@
+package log
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimit caps how fast messages are posted to a single webhook.
+// Limits are tracked per webhook URL and shared by every Logger in the process,
+// so Loggers posting to the same webhook draw from the same budget.
+// Rate limiting is opt-in: the zero value, which New leaves in place, disables
+// it. Set LogWriter.RateLimit to DefaultRateLimit to stay within Slack's limits.
+type RateLimit struct {
+	// Rate is the sustained number of messages per second.
+	Rate float64
+	// Burst is how many messages may be posted back to back before Rate applies.
+	// Defaults to 1.
+	Burst int
+}
+
+// DefaultRateLimit matches Slack's documented limit for incoming webhooks:
+// one message per second, with short bursts allowed.
+var DefaultRateLimit = RateLimit{Rate: 1, Burst: 3}
+
+// tokenBucket is a token bucket that hands out reservations.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// limiters holds the process-wide token buckets keyed by webhook URL.
+var limiters = struct {
+	sync.Mutex
+	m map[string]*tokenBucket
+}{m: make(map[string]*tokenBucket)}
+
+// wait blocks until a message may be posted to webhook under rl, or ctx is done.
+// A caller that gives up returns its token, so it doesn't delay the others.
+func (rl RateLimit) wait(ctx context.Context, webhook string) error {
+	if rl.Rate <= 0 {
+		return nil
+	}
+	b := limiterFor(webhook)
+	if err := sleep(ctx, b.reserve(rl, time.Now())); err != nil {
+		b.release()
+		return err
+	}
+	return nil
+}
+
+// limiterFor returns the shared bucket for webhook, creating it on first use.
+func limiterFor(webhook string) *tokenBucket {
+	limiters.Lock()
+	defer limiters.Unlock()
+	b, ok := limiters.m[webhook]
+	if !ok {
+		b = &tokenBucket{}
+		limiters.m[webhook] = b
+	}
+	return b
+}
+
+// reserve takes a token and returns how long the caller must wait before using it.
+// The bucket adopts rl's parameters, so the most recent configuration for a webhook wins.
+func (b *tokenBucket) reserve(rl RateLimit, now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rate = rl.Rate
+	b.burst = float64(max(rl.Burst, 1))
+	if b.last.IsZero() {
+		b.tokens = b.burst
+	} else {
+		b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.rate, b.burst)
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// release returns a token taken by reserve that went unused.
+func (b *tokenBucket) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+1, b.burst)
+}
@@ -0,0 +1,91 @@
+package log
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketReserve(t *testing.T) {
+	var b tokenBucket
+	rl := RateLimit{Rate: 2, Burst: 2}
+	now := time.Now()
+	waits := []time.Duration{0, 0, 500 * time.Millisecond, time.Second}
+	for i, want := range waits {
+		if got := b.reserve(rl, now); got != want {
+			t.Errorf("reservation %d: expected wait %v, got %v", i, want, got)
+		}
+	}
+	// One second later the backlog of two reservations is paid off.
+	if got := b.reserve(rl, now.Add(time.Second)); got != 500*time.Millisecond {
+		t.Errorf("expected wait 500ms after refill, got %v", got)
+	}
+}
+
+func TestTokenBucketBurstCap(t *testing.T) {
+	var b tokenBucket
+	rl := RateLimit{Rate: 1}
+	now := time.Now()
+	b.reserve(rl, now)
+	// Idle time never accumulates more than Burst tokens.
+	later := now.Add(time.Hour)
+	if got := b.reserve(rl, later); got != 0 {
+		t.Errorf("expected no wait, got %v", got)
+	}
+	if got := b.reserve(rl, later); got != time.Second {
+		t.Errorf("expected 1s wait, got %v", got)
+	}
+}
+
+func TestRateLimitSharedAcrossLoggers(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	rl := RateLimit{Rate: 20, Burst: 1}
+	first := New(srv.URL)
+	first.Writer.RateLimit = rl
+	second := New(srv.URL)
+	second.Writer.RateLimit = rl
+
+	start := time.Now()
+	for range 3 {
+		first.Info("first")
+		second.Info("second")
+	}
+	// Six posts at 20/s with a burst of one need at least five intervals of 50ms.
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Errorf("expected posts to be spaced out, took only %v", elapsed)
+	}
+	if msgs := getMessages(); len(msgs) != 6 {
+		t.Fatalf("expected 6 messages, got %d", len(msgs))
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	var rl RateLimit
+	start := time.Now()
+	for range 100 {
+		rl.wait(context.Background(), "https://example.com/webhook")
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("zero RateLimit should not wait, took %v", elapsed)
+	}
+}
+
+func TestRateLimitCancelReturnsToken(t *testing.T) {
+	const webhook = "https://hooks.example.com/cancel"
+	rl := RateLimit{Rate: 1, Burst: 1}
+	if err := rl.wait(context.Background(), webhook); err != nil {
+		t.Fatalf("first wait returned error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := rl.wait(ctx, webhook); err == nil {
+		t.Fatal("expected the cancelled wait to fail")
+	}
+	// The cancelled caller gave its token back, so the next one waits about a
+	// second rather than two.
+	if got := limiterFor(webhook).reserve(rl, time.Now()); got > time.Second {
+		t.Errorf("expected a wait of at most 1s, got %v", got)
+	}
+}
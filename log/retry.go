@@ -0,0 +1,136 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed Slack posts are retried.
+// The zero value disables retries, so every message is attempted exactly once.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseBackoff is the wait before the first retry; it doubles on every attempt.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the exponential backoff. It does not cap a Retry-After sent by Slack.
+	MaxBackoff time.Duration
+	// Jitter is the fraction (0 to 1) of each backoff that is randomized.
+	Jitter float64
+	// RetryableStatus lists the HTTP status codes worth retrying.
+	// If nil, DefaultRetryableStatus is used. Transport errors are always retried.
+	RetryableStatus []int
+}
+
+// DefaultRetryableStatus holds the status codes retried when RetryPolicy.RetryableStatus is nil.
+var DefaultRetryableStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryPolicy is a sensible policy for Slack webhooks.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseBackoff: 500 * time.Millisecond,
+	MaxBackoff:  30 * time.Second,
+	Jitter:      0.2,
+}
+
+// do calls send until it succeeds, returns a non-retryable error, or the attempts run out.
+// The last error is returned, or ctx's error if it is done while waiting to retry.
+func (p RetryPolicy) do(ctx context.Context, send func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = send()
+		if err == nil || attempt >= p.MaxAttempts || !p.retryable(err) {
+			return err
+		}
+		if err := sleep(ctx, p.wait(attempt, err)); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep waits for d or until ctx is done, whichever comes first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryable reports whether err is worth another attempt.
+func (p RetryPolicy) retryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ErrNoToken) || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	codes := p.RetryableStatus
+	if codes == nil {
+		codes = DefaultRetryableStatus
+	}
+	var aerr *APIError
+	if errors.As(err, &aerr) {
+		if aerr.StatusCode == http.StatusOK {
+			return slices.Contains(retryableAPICodes, aerr.Code)
+		}
+		return slices.Contains(codes, aerr.StatusCode)
+	}
+	var werr *WebhookError
+	if !errors.As(err, &werr) {
+		return true
+	}
+	return slices.Contains(codes, werr.StatusCode)
+}
+
+// wait returns how long to sleep after the given failed attempt.
+// A Retry-After from Slack takes precedence over the computed backoff.
+func (p RetryPolicy) wait(attempt int, err error) time.Duration {
+	var werr *WebhookError
+	if errors.As(err, &werr) && werr.RetryAfter > 0 {
+		return werr.RetryAfter
+	}
+	var aerr *APIError
+	if errors.As(err, &aerr) && aerr.RetryAfter > 0 {
+		return aerr.RetryAfter
+	}
+	d := p.BaseBackoff << min(attempt-1, 32)
+	if d <= 0 || (p.MaxBackoff > 0 && d > p.MaxBackoff) {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		d -= time.Duration(p.Jitter * rand.Float64() * float64(d))
+	}
+	return d
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
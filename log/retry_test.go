@@ -0,0 +1,166 @@
+package log
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newScriptedServer creates an httptest server that answers each request with the
+// next status from script, then 200 once the script is exhausted.
+// Returns the server and a function reporting how many requests were received.
+func newScriptedServer(t *testing.T, retryAfter string, script ...int) (*httptest.Server, func() int) {
+	t.Helper()
+	var (
+		mu    sync.Mutex
+		count int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		i := count
+		count++
+		mu.Unlock()
+		if i >= len(script) {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if script[i] == http.StatusTooManyRequests && retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(script[i])
+	}))
+	get := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return count
+	}
+	return srv, get
+}
+
+func TestRetryRecovers(t *testing.T) {
+	srv, requests := newScriptedServer(t, "", http.StatusTooManyRequests, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Retry = RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond}
+	logger.Info("eventually delivered")
+	if err := logger.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := requests(); got != 3 {
+		t.Errorf("expected 3 requests, got %d", got)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	srv, requests := newScriptedServer(t, "", http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Retry = RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond}
+	logger.Info("never delivered")
+	var werr *WebhookError
+	if !errors.As(logger.Err(), &werr) || werr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 *WebhookError, got %v", logger.Err())
+	}
+	if got := requests(); got != 2 {
+		t.Errorf("expected 2 requests, got %d", got)
+	}
+}
+
+func TestRetrySkipsPermanentErrors(t *testing.T) {
+	srv, requests := newScriptedServer(t, "", http.StatusNotFound)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Retry = RetryPolicy{MaxAttempts: 5, BaseBackoff: time.Millisecond}
+	logger.Info("not retried")
+	if logger.Err() == nil {
+		t.Fatal("expected error")
+	}
+	if got := requests(); got != 1 {
+		t.Errorf("expected 1 request, got %d", got)
+	}
+}
+
+func TestRetryCustomStatus(t *testing.T) {
+	srv, requests := newScriptedServer(t, "", http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Retry = RetryPolicy{
+		MaxAttempts:     3,
+		BaseBackoff:     time.Millisecond,
+		RetryableStatus: []int{http.StatusTooManyRequests},
+	}
+	logger.Info("503 not retryable here")
+	if logger.Err() == nil {
+		t.Fatal("expected error")
+	}
+	if got := requests(); got != 1 {
+		t.Errorf("expected 1 request, got %d", got)
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	srv, requests := newScriptedServer(t, "1", http.StatusTooManyRequests)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Retry = RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	start := time.Now()
+	logger.Info("rate limited")
+	if err := logger.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected to wait for Retry-After, only waited %v", elapsed)
+	}
+	if got := requests(); got != 2 {
+		t.Errorf("expected 2 requests, got %d", got)
+	}
+}
+
+func TestRetryWait(t *testing.T) {
+	p := RetryPolicy{BaseBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 100 * time.Millisecond},
+		{attempt: 2, want: 200 * time.Millisecond},
+		{attempt: 3, want: 300 * time.Millisecond},
+		{attempt: 60, want: 300 * time.Millisecond},
+	}
+	for _, test := range tests {
+		if got := p.wait(test.attempt, errors.New("boom")); got != test.want {
+			t.Errorf("wait(%d) = %v, want %v", test.attempt, got, test.want)
+		}
+	}
+
+	p.Jitter = 0.5
+	for range 100 {
+		if got := p.wait(1, errors.New("boom")); got < 50*time.Millisecond || got > 100*time.Millisecond {
+			t.Fatalf("jittered wait out of range: %v", got)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("3"); got != 3*time.Second {
+		t.Errorf("expected 3s, got %v", got)
+	}
+	if got := parseRetryAfter(""); got != 0 {
+		t.Errorf("expected 0, got %v", got)
+	}
+	if got := parseRetryAfter("garbage"); got != 0 {
+		t.Errorf("expected 0, got %v", got)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(date); got <= 59*time.Minute {
+		t.Errorf("expected about an hour, got %v", got)
+	}
+}
@@ -0,0 +1,106 @@
+package log
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Router picks the destinations of each message by rules instead of by level alone,
+// so one Logger can feed several team channels.
+type Router struct {
+	// Destinations names the webhook URLs and channel IDs routes send to.
+	Destinations map[string]string
+	// Routes are evaluated in order.
+	Routes []Route
+	// FanOut sends a message to the destinations of every matching route.
+	// Otherwise only the first matching route is used.
+	FanOut bool
+	// Default names the destinations of messages no route matches. If empty,
+	// they go to the LogWriter's destination for their level.
+	Default []string
+}
+
+// Route sends the messages it matches to named destinations.
+// Empty conditions match every message; a route matches when all of its
+// conditions do.
+type Route struct {
+	// Levels are the levels matched.
+	Levels []LogLevel
+	// Prefix is matched against the start of the Logger's prefix.
+	Prefix string
+	// Message is matched against the unescaped message.
+	Message *regexp.Regexp
+	// Fields must all be present with exactly these values, e.g. {"team": "payments"}.
+	Fields map[string]string
+	// To names the destinations in Router.Destinations.
+	To []string
+}
+
+// match reports whether rt matches r.
+func (rt Route) match(r Record) bool {
+	if len(rt.Levels) > 0 && !slices.Contains(rt.Levels, r.Level) {
+		return false
+	}
+	if !strings.HasPrefix(r.Prefix, rt.Prefix) {
+		return false
+	}
+	if rt.Message != nil && !rt.Message.MatchString(r.Message) {
+		return false
+	}
+	for key, value := range rt.Fields {
+		if !slices.Contains(r.Fields, Field{Key: key, Value: value}) {
+			return false
+		}
+	}
+	return true
+}
+
+// route returns the names of the destinations r is sent to.
+func (rt *Router) route(r Record) []string {
+	var names []string
+	for _, route := range rt.Routes {
+		if !route.match(r) {
+			continue
+		}
+		names = append(names, route.To...)
+		if !rt.FanOut {
+			break
+		}
+	}
+	if len(names) == 0 {
+		names = rt.Default
+	}
+	return names
+}
+
+// destinations returns the destinations r is posted to, each once: those
+// picked by the Router or the level's, then the level's extra Destinations.
+func (lw LogWriter) destinations(r Record) ([]Destination, error) {
+	var urls []string
+	if lw.Router != nil {
+		for _, name := range lw.Router.route(r) {
+			url, ok := lw.Router.Destinations[name]
+			if !ok {
+				return nil, fmt.Errorf("log: unknown destination %q", name)
+			}
+			urls = append(urls, url)
+		}
+	}
+	if len(urls) == 0 {
+		urls = []string{lw.webhook(r.Level)}
+	}
+	var dests []Destination
+	for _, url := range urls {
+		dests = append(dests, Destination{URL: url})
+	}
+	dests = append(dests, lw.Destinations[r.Level]...)
+	var unique []Destination
+	for _, d := range dests {
+		if !slices.ContainsFunc(unique, func(u Destination) bool { return u.URL == d.URL }) {
+			unique = append(unique, d)
+		}
+	}
+	return unique, nil
+}
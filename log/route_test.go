@@ -0,0 +1,143 @@
+package log
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// newDestServers starts a test server per name and returns a Router
+// destinations map along with a function listing what each received.
+func newDestServers(t *testing.T, names ...string) (map[string]string, func(name string) []string, func()) {
+	t.Helper()
+	dests := make(map[string]string)
+	gets := make(map[string]func() []string)
+	var srvs []*httptest.Server
+	for _, name := range names {
+		srv, get := newTestServer(t)
+		srvs = append(srvs, srv)
+		dests[name] = srv.URL
+		gets[name] = get
+	}
+	closeAll := func() {
+		for _, srv := range srvs {
+			srv.Close()
+		}
+	}
+	return dests, func(name string) []string { return gets[name]() }, closeAll
+}
+
+func TestRouterFirstMatch(t *testing.T) {
+	dests, received, closeAll := newDestServers(t, "payments", "incidents", "general", "fallback")
+	defer closeAll()
+
+	logger := New(dests["fallback"])
+	logger.Writer.Router = &Router{
+		Destinations: dests,
+		Routes: []Route{
+			{Fields: map[string]string{"team": "payments"}, To: []string{"payments"}},
+			{Levels: []LogLevel{LevelError}, To: []string{"incidents"}},
+			{Message: regexp.MustCompile(`^deploy`), To: []string{"general"}},
+		},
+	}
+	logger.Errorw("card declined", "team", "payments")
+	logger.Errorw("disk full", "team", "infra")
+	logger.Info("deploy finished")
+	logger.Info("nothing special")
+
+	want := map[string][]string{
+		"payments":  {"ERRO: card declined team=payments"},
+		"incidents": {"ERRO: disk full team=infra"},
+		"general":   {"INFO: deploy finished"},
+		"fallback":  {"INFO: nothing special"},
+	}
+	for name, msgs := range want {
+		if got := received(name); strings.Join(got, "\n") != strings.Join(msgs, "\n") {
+			t.Errorf("%s: expected %q, got %q", name, msgs, got)
+		}
+	}
+}
+
+func TestRouterFanOut(t *testing.T) {
+	dests, received, closeAll := newDestServers(t, "payments", "incidents", "archive")
+	defer closeAll()
+
+	logger := New("")
+	logger.SetPrefix("[billing] ")
+	logger.Writer.Router = &Router{
+		Destinations: dests,
+		FanOut:       true,
+		Routes: []Route{
+			{Prefix: "[billing]", To: []string{"payments"}},
+			{Levels: []LogLevel{LevelError}, To: []string{"incidents", "payments"}},
+		},
+		Default: []string{"archive"},
+	}
+	logger.Error("charge failed")
+	logger.Info("invoice sent")
+	logger.SetPrefix("[auth] ")
+	logger.Info("login")
+
+	if got := received("payments"); len(got) != 2 {
+		t.Errorf("expected each message once in payments, got %q", got)
+	}
+	if got := received("incidents"); len(got) != 1 || got[0] != "[billing] ERRO: charge failed\n" {
+		t.Errorf("unexpected incidents messages: %q", got)
+	}
+	if got := received("archive"); len(got) != 1 || got[0] != "[auth] INFO: login" {
+		t.Errorf("unexpected default route messages: %q", got)
+	}
+}
+
+func TestRouterUnknownDestination(t *testing.T) {
+	logger := New("")
+	logger.Writer.Router = &Router{Routes: []Route{{To: []string{"missing"}}}}
+	logger.Info("lost")
+	if err := logger.Err(); err == nil || !strings.Contains(err.Error(), `unknown destination "missing"`) {
+		t.Errorf("expected an unknown destination error, got %v", err)
+	}
+}
+
+func TestRouteMatch(t *testing.T) {
+	r := Record{Level: LevelWarning, Prefix: "[api] ", Message: "slow <query>", Fields: []Field{{Key: "team", Value: "core"}}}
+	tests := []struct {
+		name  string
+		route Route
+		want  bool
+	}{
+		{name: "empty", route: Route{}, want: true},
+		{name: "level", route: Route{Levels: []LogLevel{LevelError, LevelWarning}}, want: true},
+		{name: "other level", route: Route{Levels: []LogLevel{LevelError}}, want: false},
+		{name: "prefix", route: Route{Prefix: "[api]"}, want: true},
+		{name: "unescaped message", route: Route{Message: regexp.MustCompile(`<query>`)}, want: true},
+		{name: "field", route: Route{Fields: map[string]string{"team": "core"}}, want: true},
+		{name: "missing field", route: Route{Fields: map[string]string{"team": "core", "env": "prod"}}, want: false},
+	}
+	for _, test := range tests {
+		if got := test.route.match(r); got != test.want {
+			t.Errorf("%s: expected %v, got %v", test.name, test.want, got)
+		}
+	}
+}
+
+func TestRouterErrorsJoined(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer down.Close()
+	dests, received, closeAll := newDestServers(t, "up")
+	defer closeAll()
+	dests["down"] = down.URL
+
+	logger := New("")
+	logger.Writer.Router = &Router{Destinations: dests, Default: []string{"down", "up"}}
+	logger.Info("partial")
+	if logger.Err() == nil {
+		t.Error("expected the failing destination's error")
+	}
+	if got := received("up"); len(got) != 1 {
+		t.Errorf("expected the healthy destination to receive the message, got %q", got)
+	}
+}
@@ -2,15 +2,24 @@ package log
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 )
 
 // LogWriter represents a writer for logging messages to Slack.
-// It contains the webhook URLs for different log levels and the log level itself.
+// It contains the destinations for different log levels and the log level itself.
+// A destination is either an incoming webhook URL or, with Token set, a channel
+// ID such as "C0123ABCDEF" that is posted to with the Web API's chat.postMessage.
 type LogWriter struct {
 	Log     string
 	Error   string
@@ -21,6 +30,77 @@ type LogWriter struct {
 
 	prefix string
 	Level  LogLevel
+
+	// Retry controls how failed posts are retried. The zero value disables retries.
+	Retry RetryPolicy
+	// RateLimit caps how fast each webhook is posted to; posts wait for their turn.
+	// It is opt-in: the zero value, which New leaves in place, disables rate
+	// limiting. Use DefaultRateLimit to match Slack's limits.
+	RateLimit RateLimit
+	// Breaker stops posting to destinations that keep failing.
+	// The zero value disables it.
+	Breaker CircuitBreaker
+	// Client is the HTTP client used for every post. If nil, http.DefaultClient is used.
+	// Set it to configure proxies, custom CAs, mTLS or an instrumented RoundTripper.
+	Client *http.Client
+	// Timeout bounds each HTTP request to Slack. Zero uses DefaultTimeout;
+	// a negative value disables the timeout.
+	Timeout time.Duration
+	// Token is the bot token (xoxb-...) used for destinations that are channel IDs.
+	Token string
+	// APIURL is the base URL of the Slack Web API. Defaults to DefaultAPIURL;
+	// point it at a fake server in tests.
+	APIURL string
+	// BroadcastErrors also shows error level replies in a thread in the channel.
+	BroadcastErrors bool
+	// Router picks destinations by rules instead of by level. If nil, or if
+	// no route matches and it has no Default, the level's destination is used.
+	Router *Router
+	// Destinations adds destinations per level, each with its own retry
+	// policy, that messages are posted to alongside the level's.
+	Destinations map[LogLevel][]Destination
+	// Fallbacks lists per level where messages go, in order, once posting to
+	// their destination has failed and its retries are exhausted.
+	Fallbacks map[LogLevel][]Fallback
+	// Identity sets the username, icon and channel messages are posted with.
+	Identity Identity
+	// Identities overrides Identity per level; empty fields fall back to Identity.
+	Identities map[LogLevel]Identity
+	// UploadThreshold uploads messages longer than this many bytes as a text
+	// file when posting to a channel, with just their first line as the
+	// message. Zero disables uploads.
+	UploadThreshold int
+	// ContextFields extracts key/value pairs, such as trace or request IDs, from
+	// the context passed to the *Context logging methods. They are appended to
+	// the message as key=value.
+	ContextFields func(ctx context.Context) []any
+	// Formatter renders each message into the payload posted to Slack.
+	// If nil, TextFormatter is used.
+	Formatter Formatter
+	// Colors overrides the attachment color bar per level when Formatter is an
+	// AttachmentFormatter, as a hex code like "#439FE0" or one of "good",
+	// "warning" and "danger". The formatter's own Colors take precedence.
+	Colors map[LogLevel]string
+	// AllowMentions keeps Slack mentions such as <!here>, <!channel>, <@U123>
+	// and <#C123> in messages and fields intact. Otherwise every &, < and > is
+	// escaped, so logged content can't ping anyone or inject links.
+	AllowMentions bool
+	// CodeBlock wraps the message body in a fenced code block.
+	CodeBlock bool
+	// MaxTextLen is the longest message text posted, in bytes. Zero uses
+	// Slack's limit of 40,000; values below 100 are raised to 100.
+	MaxTextLen int
+	// Oversize decides what happens to longer text. Defaults to OversizeTruncate.
+	Oversize OversizePolicy
+
+	// fields holds the key/value pairs added with Logger.With.
+	fields []any
+	// thread is the thread set with Logger.Thread.
+	thread *thread
+	// fanout collects the errors of a message queued for several destinations.
+	fanout *fanOutGroup
+	queue  *asyncQueue
+	batch  *batcher
 }
 
 // LogLevel represents the log level for the LogWriter, providing type safety.
@@ -34,6 +114,15 @@ const (
 	LevelTrace
 )
 
+// levelTags are the short level names messages are tagged with.
+var levelTags = [...]string{
+	LevelError:   "ERRO",
+	LevelWarning: "WARN",
+	LevelInfo:    "INFO",
+	LevelDebug:   "DEBG",
+	LevelTrace:   "TRCE",
+}
+
 type Logger struct {
 	Writer LogWriter
 
@@ -51,7 +140,11 @@ func (l *Logger) setErr(err error) {
 }
 
 // Err returns the error for the Logger.
+// For an async Logger this includes the last delivery error.
 func (l *Logger) Err() error {
+	if l.err == nil && l.Writer.queue != nil {
+		return l.Writer.queue.lastErr()
+	}
 	return l.err
 }
 
@@ -95,93 +188,346 @@ func Default() *Logger {
 	return std
 }
 
-// info writes an info level message to Slack.
-// Returns the number of bytes written and any error encountered.
-func (lw LogWriter) info(p []byte) (n int, err error) {
-	if lw.Level < LevelInfo {
-		return
+// webhook returns the webhook URL messages at level are posted to.
+// Info messages go to the Info webhook, or to Log if Info is empty.
+func (lw LogWriter) webhook(level LogLevel) string {
+	switch level {
+	case LevelError:
+		return lw.Error
+	case LevelWarning:
+		return lw.Warning
+	case LevelDebug:
+		return lw.Debug
+	case LevelTrace:
+		return lw.Trace
+	default:
+		if lw.Info != "" {
+			return lw.Info
+		}
+		return lw.Log
 	}
-	buf := make([]byte, len(p))
-	copy(buf, p)
-	strLine := fmt.Sprintf("INFO: %s", string(buf))
-	return len(p), postSlack(lw.Log, strLine, lw.prefix)
 }
 
-// error writes an error level message to Slack.
+// output renders a message at level and posts it to the level's destinations.
+// The message carries the writer's fields, the context fields and kv, in that order.
 // Returns the number of bytes written and any error encountered.
-func (lw LogWriter) error(p []byte) (n int, err error) {
-	if lw.Level < LevelError {
+func (lw LogWriter) output(ctx context.Context, level LogLevel, p []byte, kv ...any) (n int, err error) {
+	if lw.Level < level {
 		return
 	}
-	buf := make([]byte, len(p))
-	copy(buf, p)
-	strLine := fmt.Sprintf("ERRO: %s", string(buf))
-	return len(p), postSlack(lw.Error, strLine, lw.prefix)
+	return len(p), lw.outputRecord(ctx, lw.record(ctx, level, p, kv...))
 }
 
-// warning writes a warning level message to Slack.
-// Returns the number of bytes written and any error encountered.
-func (lw LogWriter) warning(p []byte) (n int, err error) {
-	if lw.Level < LevelWarning {
-		return
+// outputRecord posts r to its destinations.
+func (lw LogWriter) outputRecord(ctx context.Context, r Record) error {
+	dests, err := lw.destinations(r)
+	if err != nil {
+		return err
 	}
-	buf := make([]byte, len(p))
-	copy(buf, p)
-	strLine := fmt.Sprintf("WARN: %s", string(buf))
-	return len(p), postSlack(lw.Warning, strLine, lw.prefix)
+	return lw.fanOut(dests, func(lw LogWriter, dest string) error {
+		return lw.outputTo(ctx, dest, r)
+	})
 }
 
-// debug writes a debug level message to Slack.
-// Returns the number of bytes written and any error encountered.
-func (lw LogWriter) debug(p []byte) (n int, err error) {
-	if lw.Level < LevelDebug {
-		return
+// outputTo renders r and posts it to dest.
+func (lw LogWriter) outputTo(ctx context.Context, dest string, r Record) error {
+	if lw.UploadThreshold > 0 && len(r.Message) > lw.UploadThreshold && isChannel(dest) {
+		data := []byte(r.Message)
+		r.Message = summarize(r.Message)
+		return lw.attach(ctx, dest, r, "message.txt", data)
 	}
-	buf := make([]byte, len(p))
-	copy(buf, p)
-	strLine := fmt.Sprintf("DEBG: %s", string(buf))
-	return len(p), postSlack(lw.Debug, strLine, lw.prefix)
+	payload, err := lw.format(r)
+	if err != nil {
+		return err
+	}
+	payload.ReplyBroadcast = lw.BroadcastErrors && r.Level == LevelError
+	return lw.postAll(ctx, r.Level, dest, lw.fit(payload))
 }
 
-// trace writes a trace level message to Slack.
-// Returns the number of bytes written and any error encountered.
-func (lw LogWriter) trace(p []byte) (n int, err error) {
-	if lw.Level < LevelTrace {
-		return
+// record builds the unescaped Record for a message at level.
+func (lw LogWriter) record(ctx context.Context, level LogLevel, p []byte, kv ...any) Record {
+	fields := lw.fields
+	if lw.ContextFields != nil {
+		fields = append(slices.Clip(fields), lw.ContextFields(ctx)...)
+	}
+	return Record{
+		Level:   level,
+		Message: string(p),
+		Prefix:  lw.prefix,
+		Time:    time.Now(),
+		Fields:  parseFields(append(slices.Clip(fields), kv...)),
+		Caller:  caller(),
+	}
+}
+
+// format escapes r and renders it with the writer's Formatter.
+func (lw LogWriter) format(r Record) (Payload, error) {
+	return lw.formatter().Format(lw.escape(r))
+}
+
+// formatter returns the Formatter messages are rendered with.
+// An AttachmentFormatter is handed the writer's Colors.
+func (lw LogWriter) formatter() Formatter {
+	switch f := lw.Formatter.(type) {
+	case nil:
+		return TextFormatter{}
+	case AttachmentFormatter:
+		f.writerColors = lw.Colors
+		return f
+	case *AttachmentFormatter:
+		af := *f
+		af.writerColors = lw.Colors
+		return af
+	default:
+		return f
 	}
-	buf := make([]byte, len(p))
-	copy(buf, p)
-	strLine := fmt.Sprintf("TRCE: %s", string(buf))
-	return len(p), postSlack(lw.Trace, strLine, lw.prefix)
 }
 
 // log writes a message at the default info level to Slack.
 // Returns the number of bytes written and any error encountered.
 func (lw LogWriter) log(p []byte) (n int, err error) {
-	return lw.info(p)
+	return lw.output(context.Background(), LevelInfo, p)
 }
 
 // Write implements the io.Writer interface for LogWriter.
 // Writes the message to Slack at the default info level.
 func (lw LogWriter) Write(p []byte) (n int, err error) {
-	buf := make([]byte, len(p))
-	copy(buf, p)
-	strLine := string(buf)
-	return len(p), postSlack(lw.Log, strLine, lw.prefix)
+	payload := Payload{Text: lw.prefix + escapeMrkdwn(string(p), lw.AllowMentions)}
+	return len(p), lw.postAll(context.Background(), LevelInfo, lw.Log, lw.fit(payload))
+}
+
+// postAll posts payloads in order, stopping at the first error.
+// They are posted with the level's identity, filling in only what the payload
+// doesn't set itself, in the writer's thread if it has one.
+// Otherwise, continuations of a message split for a channel are posted as
+// replies to its first part.
+func (lw LogWriter) postAll(ctx context.Context, level LogLevel, webhook string, payloads []Payload) error {
+	if lw.thread != nil {
+		ctx = context.WithValue(ctx, threadKey{}, lw.thread)
+	}
+	continued := len(payloads) > 1 && isChannel(webhook) && threadFrom(ctx) == nil
+	if continued {
+		ctx = context.WithValue(ctx, threadKey{}, newThread())
+	}
+	id := lw.identity(level)
+	for _, p := range payloads {
+		p.Identity = id.merge(p.Identity)
+		if continued {
+			p.ReplyBroadcast = false
+		}
+		if err := lw.post(ctx, level, webhook, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// post sends the payload to the webhook, or queues it if the writer is async.
+// Queued messages keep ctx's values but not its cancellation.
+func (lw LogWriter) post(ctx context.Context, level LogLevel, webhook string, p Payload) error {
+	if lw.batch != nil || lw.queue != nil {
+		j := job{ctx: context.WithoutCancel(ctx), lw: lw, level: level, webhook: webhook, payload: p, groups: []*fanOutGroup{lw.fanout}}
+		if lw.fanout != nil {
+			lw.fanout.add()
+		}
+		var err error
+		if lw.batch != nil {
+			err = lw.batch.add(j)
+		} else {
+			err = lw.queue.enqueue(j)
+		}
+		if err != nil && lw.fanout != nil {
+			// The job wasn't queued; fanOut reports err itself.
+			lw.fanout.done(webhook, nil)
+		}
+		return err
+	}
+	return lw.send(ctx, level, webhook, p)
 }
 
-// postSlack sends a message to a Slack webhook.
-// Returns any error encountered during the HTTP request.
-func postSlack(webhook, text, prefix string) error {
-	if prefix != "" {
-		text = prefix + text
-	}
-	values := map[string]string{"text": text}
-	jsonValue, _ := json.Marshal(values)
-	_, err := http.Post(webhook, "application/json", bytes.NewBuffer(jsonValue))
+// send posts the payload to the webhook, falling back to the level's
+// Fallbacks if that fails.
+func (lw LogWriter) send(ctx context.Context, level LogLevel, webhook string, p Payload) error {
+	return lw.orFallback(ctx, level, webhook, p, lw.sendPrimary(ctx, webhook, p))
+}
+
+// orFallback returns err, the outcome of posting p to webhook, unless it
+// failed and the level has Fallbacks, in which case they are tried instead.
+func (lw LogWriter) orFallback(ctx context.Context, level LogLevel, webhook string, p Payload, err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || len(lw.Fallbacks[level]) == 0 {
+		return err
+	}
+	return lw.fallback(ctx, level, webhook, p, err)
+}
+
+// sendPrimary posts the payload to the webhook, in the thread carried by ctx if it is a channel.
+func (lw LogWriter) sendPrimary(ctx context.Context, webhook string, p Payload) error {
+	if t := threadFrom(ctx); t != nil && isChannel(webhook) {
+		return t.send(webhook, p, func(p Payload) (string, error) {
+			if p.file != nil && p.ThreadTS == "" {
+				return lw.deliverParentUpload(ctx, webhook, p)
+			}
+			posted, err := lw.deliver(ctx, webhook, p)
+			return posted.TS, err
+		})
+	}
+	p.ThreadTS, p.ReplyBroadcast = "", false
+	_, err := lw.deliver(ctx, webhook, p)
 	return err
 }
 
+// deliver posts the payload to the webhook. For channels it returns the
+// channel and ts identifying the posted message.
+func (lw LogWriter) deliver(ctx context.Context, webhook string, p Payload) (posted postedMessage, err error) {
+	err = lw.retry(ctx, webhook, func(ctx context.Context) error {
+		if isChannel(webhook) && p.file != nil {
+			return lw.upload(ctx, webhook, p)
+		}
+		if isChannel(webhook) {
+			posted, err = lw.postAPI(ctx, webhook, p)
+			return err
+		}
+		return postSlack(ctx, lw.client(), webhook, p)
+	})
+	return posted, err
+}
+
+// deliverParentUpload starts a thread with an upload. Slack doesn't report the
+// ts of the message sharing an uploaded file, so the text is posted on its own
+// with chat.postMessage and the file is uploaded as the first reply.
+func (lw LogWriter) deliverParentUpload(ctx context.Context, channel string, p Payload) (string, error) {
+	file := Payload{file: p.file}
+	p.file = nil
+	posted, err := lw.deliver(ctx, channel, p)
+	if err != nil {
+		return "", err
+	}
+	file.ThreadTS = posted.TS
+	_, err = lw.deliver(ctx, channel, file)
+	return posted.TS, err
+}
+
+// retry calls fn for dest, retrying according to lw.Retry. Every attempt waits
+// for dest's rate limit, goes through its circuit breaker and runs under
+// lw.Timeout. ctx bounds the whole delivery, including waits between attempts.
+func (lw LogWriter) retry(ctx context.Context, dest string, fn func(ctx context.Context) error) error {
+	return lw.Retry.do(ctx, func() error {
+		if err := lw.RateLimit.wait(ctx, dest); err != nil {
+			return err
+		}
+		return lw.Breaker.call(ctx, dest, func() error {
+			ctx, cancel := lw.requestContext(ctx)
+			defer cancel()
+			return fn(ctx)
+		})
+	})
+}
+
+// DefaultTimeout bounds each request to Slack when LogWriter.Timeout is zero.
+const DefaultTimeout = 10 * time.Second
+
+// client returns the HTTP client to post with.
+func (lw LogWriter) client() *http.Client {
+	if lw.Client != nil {
+		return lw.Client
+	}
+	return http.DefaultClient
+}
+
+// requestContext derives the context for a single request, applying lw.Timeout.
+func (lw LogWriter) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	switch {
+	case lw.Timeout < 0:
+		return context.WithCancel(ctx)
+	case lw.Timeout == 0:
+		return context.WithTimeout(ctx, DefaultTimeout)
+	default:
+		return context.WithTimeout(ctx, lw.Timeout)
+	}
+}
+
+// maxTextLen is the longest text Slack accepts in a single message.
+const maxTextLen = 40000
+
+// maxErrorBody caps how much of a failed response body is kept as the Slack error string.
+const maxErrorBody = 1 << 10
+
+// WebhookError is returned when Slack answers a webhook post with a non-2xx status.
+// Code holds the error string from the response body, e.g. "invalid_payload",
+// "action_prohibited", "no_service" or "channel_is_archived".
+type WebhookError struct {
+	StatusCode int
+	Code       string
+	// RetryAfter is the delay Slack asked for via the Retry-After header, if any.
+	RetryAfter time.Duration
+	// Webhook is the destination URL with its secret path segments masked.
+	Webhook string
+}
+
+func (e *WebhookError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("slack webhook %s: %d %s", e.Webhook, e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("slack webhook %s: %d %s", e.Webhook, e.StatusCode, e.Code)
+}
+
+// maskWebhook hides the secret part of a webhook URL so it can be safely logged.
+// Everything after the first path segment is replaced, along with any query or credentials.
+func maskWebhook(webhook string) string {
+	u, err := url.Parse(webhook)
+	if err != nil || u.Host == "" {
+		return "***"
+	}
+	first, rest, _ := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	masked := u.Scheme + "://" + u.Host + "/" + first
+	if rest != "" || u.RawQuery != "" {
+		masked += "/***"
+	}
+	return masked
+}
+
+// maskURLError hides the webhook secret in errors returned by net/http,
+// which quote the full request URL.
+func maskURLError(err error, webhook string) error {
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		uerr.URL = maskWebhook(webhook)
+	}
+	return err
+}
+
+// postSlack sends a message to a Slack webhook.
+// Returns any error encountered during the HTTP request, or a *WebhookError
+// if Slack rejected the message.
+func postSlack(ctx context.Context, client *http.Client, webhook string, p Payload) error {
+	jsonValue, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(jsonValue))
+	if err != nil {
+		return maskURLError(err, webhook)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return maskURLError(err, webhook)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	// Drain whatever is left so the connection can be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return &WebhookError{
+		StatusCode: resp.StatusCode,
+		Code:       strings.TrimSpace(string(body)),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		Webhook:    maskWebhook(webhook),
+	}
+}
+
 // New creates a new Logger with the specified webhook URL.
 // The webhook URL will be used for all log levels.
 func New(webhookLink string) *Logger {
@@ -215,7 +561,14 @@ func WithWriter(w LogWriter) Logger {
 }
 
 // WithWriter sets the LogWriter for the Logger.
+// An async queue or batching started on the Logger is kept unless w brings its own.
 func (l *Logger) WithWriter(w LogWriter) Logger {
+	if w.queue == nil {
+		w.queue = l.Writer.queue
+	}
+	if w.batch == nil {
+		w.batch = l.Writer.batch
+	}
 	l.Writer = w
 	return *l
 }
@@ -260,7 +613,7 @@ func Error(args ...any) {
 
 // Error writes an error level message.
 func (l *Logger) Error(args ...any) {
-	_, err := l.Writer.error(fmt.Appendln(nil, args...))
+	_, err := l.Writer.output(context.Background(), LevelError, fmt.Appendln(nil, args...))
 	l.setErr(err)
 }
 
@@ -271,7 +624,7 @@ func Errorf(format string, args ...any) {
 
 // Errorf writes a formatted error level message.
 func (l *Logger) Errorf(format string, args ...any) {
-	_, err := l.Writer.error(fmt.Appendf(nil, format, args...))
+	_, err := l.Writer.output(context.Background(), LevelError, fmt.Appendf(nil, format, args...))
 	l.setErr(err)
 }
 
@@ -282,7 +635,7 @@ func Errorln(args ...any) {
 
 // Errorln writes an error level message with a newline.
 func (l *Logger) Errorln(args ...any) {
-	_, err := l.Writer.error(fmt.Appendln(nil, args...))
+	_, err := l.Writer.output(context.Background(), LevelError, fmt.Appendln(nil, args...))
 	l.setErr(err)
 }
 
@@ -293,7 +646,7 @@ func Warning(warning string) {
 
 // Warning writes a warning level message.
 func (l *Logger) Warning(warning string) {
-	_, err := l.Writer.warning([]byte(warning))
+	_, err := l.Writer.output(context.Background(), LevelWarning, []byte(warning))
 	l.setErr(err)
 }
 
@@ -304,7 +657,7 @@ func Warningf(format string, args ...any) {
 
 // Warningf writes a formatted warning level message.
 func (l *Logger) Warningf(format string, args ...any) {
-	_, err := l.Writer.warning(fmt.Appendf(nil, format, args...))
+	_, err := l.Writer.output(context.Background(), LevelWarning, fmt.Appendf(nil, format, args...))
 	l.setErr(err)
 }
 
@@ -315,7 +668,7 @@ func Warningln(args ...any) {
 
 // Warningln writes a warning level message with a newline.
 func (l *Logger) Warningln(args ...any) {
-	_, err := l.Writer.warning(fmt.Appendln(nil, args...))
+	_, err := l.Writer.output(context.Background(), LevelWarning, fmt.Appendln(nil, args...))
 	l.setErr(err)
 }
 
@@ -326,7 +679,7 @@ func Info(info string) {
 
 // Info writes an info level message.
 func (l *Logger) Info(info string) {
-	_, err := l.Writer.info([]byte(info))
+	_, err := l.Writer.output(context.Background(), LevelInfo, []byte(info))
 	l.setErr(err)
 }
 
@@ -337,7 +690,7 @@ func Infof(format string, args ...any) {
 
 // Infof writes a formatted info level message.
 func (l *Logger) Infof(format string, args ...any) {
-	_, err := l.Writer.info(fmt.Appendf(nil, format, args...))
+	_, err := l.Writer.output(context.Background(), LevelInfo, fmt.Appendf(nil, format, args...))
 	l.setErr(err)
 }
 
@@ -348,7 +701,7 @@ func Infoln(args ...any) {
 
 // Infoln writes an info level message with a newline.
 func (l *Logger) Infoln(args ...any) {
-	_, err := l.Writer.info(fmt.Appendln(nil, args...))
+	_, err := l.Writer.output(context.Background(), LevelInfo, fmt.Appendln(nil, args...))
 	l.setErr(err)
 }
 
@@ -359,7 +712,7 @@ func Debug(debug string) {
 
 // Debug writes a debug level message.
 func (l *Logger) Debug(debug string) {
-	_, err := l.Writer.debug([]byte(debug))
+	_, err := l.Writer.output(context.Background(), LevelDebug, []byte(debug))
 	if err != nil {
 		l.err = err
 	}
@@ -371,7 +724,7 @@ func Debugf(format string, args ...any) {
 }
 
 func (l *Logger) Debugf(format string, args ...any) {
-	_, err := l.Writer.debug(fmt.Appendf(nil, format, args...))
+	_, err := l.Writer.output(context.Background(), LevelDebug, fmt.Appendf(nil, format, args...))
 	l.setErr(err)
 }
 
@@ -382,7 +735,7 @@ func Debugln(args ...any) {
 
 // Debugln writes a debug level message with a newline.
 func (l *Logger) Debugln(args ...any) {
-	_, err := l.Writer.debug(fmt.Appendln(nil, args...))
+	_, err := l.Writer.output(context.Background(), LevelDebug, fmt.Appendln(nil, args...))
 	l.setErr(err)
 }
 
@@ -393,7 +746,7 @@ func Trace(trace string) {
 
 // Trace writes a trace level message.
 func (l *Logger) Trace(trace string) {
-	_, err := l.Writer.trace([]byte(trace))
+	_, err := l.Writer.output(context.Background(), LevelTrace, []byte(trace))
 	l.setErr(err)
 }
 
@@ -404,7 +757,7 @@ func Tracef(format string, args ...any) {
 
 // Tracef writes a formatted trace level message.
 func (l *Logger) Tracef(format string, args ...any) {
-	_, err := l.Writer.trace(fmt.Appendf(nil, format, args...))
+	_, err := l.Writer.output(context.Background(), LevelTrace, fmt.Appendf(nil, format, args...))
 	l.setErr(err)
 }
 
@@ -415,7 +768,7 @@ func Traceln(args ...any) {
 
 // Traceln writes a trace level message with a newline.
 func (l *Logger) Traceln(args ...any) {
-	_, err := l.Writer.trace(fmt.Appendln(nil, args...))
+	_, err := l.Writer.output(context.Background(), LevelTrace, fmt.Appendln(nil, args...))
 	l.setErr(err)
 }
 
@@ -434,23 +787,36 @@ func Println(v ...any) {
 	std.Logln(v...)
 }
 
+// fatalFlushTimeout bounds how long Fatal waits for queued messages before exiting.
+const fatalFlushTimeout = 10 * time.Second
+
+// exit flushes the default logger and terminates the program.
+func exit() {
+	ctx, cancel := context.WithTimeout(context.Background(), fatalFlushTimeout)
+	std.Flush(ctx)
+	cancel()
+	os.Exit(1)
+}
+
 // Fatal writes a message at the default error level.
-// Subsequently, it calls os.Exit(1).
+// Subsequently, it flushes queued messages and calls os.Exit(1).
 func Fatal(v ...any) {
 	std.Error(fmt.Sprint(v...))
-	os.Exit(1)
+	exit()
 }
 
 // Fatalf writes a formatted message at the default error level.
+// Subsequently, it flushes queued messages and calls os.Exit(1).
 func Fatalf(format string, v ...any) {
 	std.Errorf(format, v...)
-	os.Exit(1)
+	exit()
 }
 
 // Fatalln writes a message at the default error level with a newline.
+// Subsequently, it flushes queued messages and calls os.Exit(1).
 func Fatalln(v ...any) {
 	std.Errorln(v...)
-	os.Exit(1)
+	exit()
 }
 
 // Panic writes a message at the default error level.
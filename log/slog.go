@@ -0,0 +1,107 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// SlogLevelTrace is the slog level mapped to LevelTrace; slog has no trace level of its own.
+const SlogLevelTrace = slog.LevelDebug - 4
+
+// SlogHandler is a slog.Handler that posts records to Slack through a LogWriter.
+// Records are routed to the LogWriter webhook for their level, and attributes
+// are rendered after the message as key=value, qualified by their groups.
+type SlogHandler struct {
+	lw LogWriter
+	// kv holds the attributes added by WithAttrs, already qualified.
+	kv     []any
+	groups []string
+}
+
+// NewSlogHandler returns a slog.Handler backed by lw.
+func NewSlogHandler(lw LogWriter) *SlogHandler {
+	return &SlogHandler{lw: lw}
+}
+
+// levelFromSlog maps a slog level onto the closest LogLevel.
+func levelFromSlog(level slog.Level) LogLevel {
+	switch {
+	case level >= slog.LevelError:
+		return LevelError
+	case level >= slog.LevelWarn:
+		return LevelWarning
+	case level >= slog.LevelInfo:
+		return LevelInfo
+	case level >= slog.LevelDebug:
+		return LevelDebug
+	default:
+		return LevelTrace
+	}
+}
+
+// Enabled reports whether the LogWriter's level lets records at level through.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return levelFromSlog(level) <= h.lw.Level
+}
+
+// Handle posts r to Slack, stamped with r's time.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	level := levelFromSlog(r.Level)
+	if h.lw.Level < level {
+		return nil
+	}
+	kv := slices.Clone(h.kv)
+	r.Attrs(func(a slog.Attr) bool {
+		kv = appendAttr(kv, h.groups, a)
+		return true
+	})
+	rec := h.lw.record(ctx, level, []byte(r.Message), kv...)
+	if !r.Time.IsZero() {
+		rec.Time = r.Time
+	}
+	return h.lw.outputRecord(ctx, rec)
+}
+
+// WithAttrs returns a handler that renders attrs on every record.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.kv = slices.Clone(h.kv)
+	for _, a := range attrs {
+		h2.kv = appendAttr(h2.kv, h.groups, a)
+	}
+	return &h2
+}
+
+// WithGroup returns a handler that qualifies later attributes with name.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(slices.Clip(h.groups), name)
+	return &h2
+}
+
+// appendAttr flattens a into key/value pairs, joining group names with dots.
+// Empty attributes are skipped and groups without a key are inlined, as slog requires.
+func appendAttr(kv []any, groups []string, a slog.Attr) []any {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return kv
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(slices.Clip(groups), a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			kv = appendAttr(kv, groups, ga)
+		}
+		return kv
+	}
+	key := a.Key
+	for i := len(groups) - 1; i >= 0; i-- {
+		key = groups[i] + "." + key
+	}
+	return append(kv, key, a.Value.Any())
+}
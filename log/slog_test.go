@@ -0,0 +1,126 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestSlogHandlerLevels(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  LogLevel
+	}{
+		{level: slog.LevelError + 4, want: LevelError},
+		{level: slog.LevelError, want: LevelError},
+		{level: slog.LevelWarn, want: LevelWarning},
+		{level: slog.LevelInfo, want: LevelInfo},
+		{level: slog.LevelInfo + 1, want: LevelInfo},
+		{level: slog.LevelDebug, want: LevelDebug},
+		{level: SlogLevelTrace, want: LevelTrace},
+	}
+	for _, test := range tests {
+		if got := levelFromSlog(test.level); got != test.want {
+			t.Errorf("levelFromSlog(%v) = %d, want %d", test.level, got, test.want)
+		}
+	}
+}
+
+func TestSlogHandlerRoutesByLevel(t *testing.T) {
+	errSrv, getErrors := newTestServer(t)
+	defer errSrv.Close()
+	warnSrv, getWarnings := newTestServer(t)
+	defer warnSrv.Close()
+	infoSrv, getInfos := newTestServer(t)
+	defer infoSrv.Close()
+
+	logger := slog.New(NewSlogHandler(LogWriter{
+		Error:   errSrv.URL,
+		Warning: warnSrv.URL,
+		Info:    infoSrv.URL,
+		Level:   LevelInfo,
+	}))
+	logger.Error("broken")
+	logger.Warn("careful")
+	logger.Info("hello")
+	logger.Debug("filtered out")
+
+	if msgs := getErrors(); len(msgs) != 1 || msgs[0] != "ERRO: broken" {
+		t.Errorf("unexpected error webhook messages: %q", msgs)
+	}
+	if msgs := getWarnings(); len(msgs) != 1 || msgs[0] != "WARN: careful" {
+		t.Errorf("unexpected warning webhook messages: %q", msgs)
+	}
+	if msgs := getInfos(); len(msgs) != 1 || msgs[0] != "INFO: hello" {
+		t.Errorf("unexpected info webhook messages: %q", msgs)
+	}
+}
+
+func TestSlogHandlerEnabled(t *testing.T) {
+	h := NewSlogHandler(LogWriter{Level: LevelWarning})
+	ctx := context.Background()
+	if !h.Enabled(ctx, slog.LevelError) || !h.Enabled(ctx, slog.LevelWarn) {
+		t.Error("expected error and warning records to be enabled")
+	}
+	if h.Enabled(ctx, slog.LevelInfo) || h.Enabled(ctx, slog.LevelDebug) {
+		t.Error("expected info and debug records to be disabled")
+	}
+}
+
+func TestSlogHandlerAttrsAndGroups(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := slog.New(NewSlogHandler(New(srv.URL).Writer))
+	logger.With("service", "api").
+		WithGroup("req").
+		With("method", "GET").
+		Info("done",
+			"status", 200,
+			slog.Group("user", "name", "jane doe"),
+			slog.Group("", "inline", true),
+			slog.Attr{},
+		)
+	logger.WithGroup("").Info("plain", "k", "v")
+
+	msgs := getMessages()
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(msgs))
+	}
+	want := `INFO: done service=api req.method=GET req.status=200 req.user.name="jane doe" req.inline=true`
+	if msgs[0] != want {
+		t.Errorf("unexpected message:\n%s\nwant:\n%s", msgs[0], want)
+	}
+	if msgs[1] != "INFO: plain k=v" {
+		t.Errorf("unexpected message: %q", msgs[1])
+	}
+}
+
+func TestSlogHandlerError(t *testing.T) {
+	h := NewSlogHandler(New("http://127.0.0.1:1").Writer)
+	r := slog.NewRecord(time.Now(), slog.LevelError, "should fail", 0)
+	if err := h.Handle(context.Background(), r); err == nil {
+		t.Fatal("expected error from bad webhook URL")
+	}
+}
+
+func TestSlogHandlerKeepsRecordTime(t *testing.T) {
+	srv, getPayloads := newPayloadServer(t)
+	defer srv.Close()
+
+	lw := New(srv.URL).Writer
+	lw.Formatter = AttachmentFormatter{}
+	r := slog.NewRecord(time.Unix(1000, 0), slog.LevelInfo, "event", 0)
+	if err := NewSlogHandler(lw).Handle(context.Background(), r); err != nil {
+		t.Fatalf("Handle returned error: %v", err)
+	}
+	payloads := getPayloads()
+	if len(payloads) != 1 {
+		t.Fatalf("expected 1 payload, got %d", len(payloads))
+	}
+	a := payloads[0]["attachments"].([]any)[0].(map[string]any)
+	if a["ts"] != float64(1000) {
+		t.Errorf("expected the record's time, got ts %v", a["ts"])
+	}
+}
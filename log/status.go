@@ -0,0 +1,112 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// StatusMessage is a handle to a posted message that can be replaced in place,
+// e.g. to turn "deploy started" into "deploy finished" or to advance a progress bar.
+// Messages are edited with the Web API's chat.update; for webhook destinations,
+// which can't edit messages, every update is posted as a new message.
+type StatusMessage struct {
+	mu sync.Mutex
+	lw LogWriter
+	// dests are the destinations picked for the first post; updates go to the same ones.
+	dests []Destination
+	// posted identifies the message in each channel it was posted to.
+	posted map[string]postedMessage
+	err    error
+}
+
+// updateMessage is the body of a chat.update call.
+type updateMessage struct {
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+	Payload
+}
+
+// Status posts a message at level using the default logger and returns a handle to update it.
+func Status(level LogLevel, msg string, kv ...any) *StatusMessage {
+	return std.Status(level, msg, kv...)
+}
+
+// Status posts a message at level, followed by key/value pairs, and returns a
+// handle to update it. The message is posted synchronously, even on an async
+// Logger, so the handle knows which message to edit. A message below the
+// Logger's level is not posted until an update raises it above.
+func (l *Logger) Status(level LogLevel, msg string, kv ...any) *StatusMessage {
+	lw := l.Writer
+	// Status messages bypass the async queue and batching.
+	lw.queue, lw.batch = nil, nil
+	s := &StatusMessage{lw: lw, posted: make(map[string]postedMessage)}
+	l.setErr(s.Update(level, msg, kv...))
+	return s
+}
+
+// Update replaces the message with msg at level, rendered with the Logger's
+// Formatter, so the level tag, emoji or attachment color follow the new level.
+// The message is posted to every destination picked for its first post, each
+// updated on its own; a destination that fails falls back to the level's Fallbacks.
+func (s *StatusMessage) Update(level LogLevel, msg string, kv ...any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lw.Level < level {
+		return nil
+	}
+	ctx := context.Background()
+	r := s.lw.record(ctx, level, []byte(msg), kv...)
+	p, err := s.lw.format(r)
+	if err != nil {
+		return s.setErr(err)
+	}
+	// A status is a single message, so oversized text is always truncated.
+	lw := s.lw
+	lw.Oversize = OversizeTruncate
+	p = lw.fit(p)[0]
+	p.Identity = s.lw.identity(level).merge(p.Identity)
+	if s.dests == nil {
+		if s.dests, err = s.lw.destinations(r); err != nil {
+			return s.setErr(err)
+		}
+	}
+	return s.setErr(s.lw.fanOut(s.dests, func(lw LogWriter, dest string) error {
+		return lw.orFallback(ctx, level, dest, p, s.send(ctx, lw, dest, p))
+	}))
+}
+
+// send updates the message in dest, or posts it if it hasn't been posted
+// there yet or dest can't edit messages. The caller must hold s.mu.
+func (s *StatusMessage) send(ctx context.Context, lw LogWriter, dest string, p Payload) error {
+	if posted, ok := s.posted[dest]; ok {
+		return lw.retry(ctx, dest, func(ctx context.Context) error {
+			return lw.callAPI(ctx, "chat.update", updateMessage{Channel: posted.Channel, TS: posted.TS, Payload: p}, nil)
+		})
+	}
+	posted, err := lw.deliver(ctx, dest, p)
+	if posted.TS != "" {
+		s.posted[dest] = posted
+	}
+	return err
+}
+
+// Updatef replaces the message with a formatted message at level.
+func (s *StatusMessage) Updatef(level LogLevel, format string, args ...any) error {
+	return s.Update(level, fmt.Sprintf(format, args...))
+}
+
+// setErr records err, if non-nil, and returns it. The caller must hold s.mu.
+func (s *StatusMessage) setErr(err error) error {
+	if err != nil {
+		s.err = err
+	}
+	return err
+}
+
+// Err returns the last error posting or updating the message.
+func (s *StatusMessage) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
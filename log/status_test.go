@@ -0,0 +1,162 @@
+package log
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStatusUpdate(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+
+	logger := newAPILogger(f, "C0123")
+	logger.Writer.Formatter = AttachmentFormatter{}
+	status := logger.Status(LevelInfo, "deploy started", "version", "1.4.2")
+	if err := status.Update(LevelError, "deploy failed", "version", "1.4.2"); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+
+	calls := f.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 calls, got %d", len(calls))
+	}
+	if calls[0].Method != "chat.postMessage" || calls[1].Method != "chat.update" {
+		t.Fatalf("unexpected methods: %s, %s", calls[0].Method, calls[1].Method)
+	}
+	update := calls[1].Body
+	if update["channel"] != "C0123" || update["ts"] != "1700000000.000001" {
+		t.Errorf("update does not target the posted message: %v", update)
+	}
+	attachment := update["attachments"].([]any)[0].(map[string]any)
+	if attachment["color"] != defaultColors[LevelError] || attachment["text"] != "deploy failed" {
+		t.Errorf("unexpected updated attachment: %v", attachment)
+	}
+}
+
+func TestStatusBelowLevel(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+
+	logger := newAPILogger(f, "C0123")
+	logger.Writer.Level = LevelInfo
+	status := logger.Status(LevelDebug, "waiting for lock")
+	status.Update(LevelDebug, "still waiting")
+	if calls := f.Calls(); len(calls) != 0 {
+		t.Fatalf("expected nothing posted below the level, got %d calls", len(calls))
+	}
+	status.Update(LevelWarning, "lock timed out")
+	status.Update(LevelInfo, "lock acquired")
+
+	calls := f.Calls()
+	if len(calls) != 2 || calls[0].Method != "chat.postMessage" || calls[1].Method != "chat.update" {
+		t.Fatalf("unexpected calls: %+v", calls)
+	}
+	if calls[1].Body["text"] != "INFO: lock acquired" {
+		t.Errorf("unexpected update text: %q", calls[1].Body["text"])
+	}
+}
+
+func TestStatusWebhook(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	status := logger.Status(LevelInfo, "deploy started")
+	status.Updatef(LevelInfo, "deploy %d%% done", 50)
+	if msgs := getMessages(); len(msgs) != 2 || msgs[1] != "INFO: deploy 50% done" {
+		t.Errorf("expected every update posted as a new message, got %q", msgs)
+	}
+}
+
+func TestStatusUpdateError(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+
+	logger := newAPILogger(f, "C0123")
+	status := logger.Status(LevelInfo, "started")
+	f.respond = func(apiCall) (int, map[string]any) {
+		return http.StatusOK, map[string]any{"ok": false, "error": "message_not_found"}
+	}
+	err := status.Update(LevelInfo, "finished")
+	var aerr *APIError
+	if !errors.As(err, &aerr) || aerr.Method != "chat.update" || aerr.Code != "message_not_found" {
+		t.Fatalf("expected message_not_found *APIError, got %v", err)
+	}
+	if status.Err() != err {
+		t.Errorf("expected Err to return the update error, got %v", status.Err())
+	}
+}
+
+func TestStatusFanOut(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+
+	logger := newAPILogger(f, "C0123")
+	logger.Writer.Destinations = map[LogLevel][]Destination{LevelInfo: {{URL: "C0456"}}}
+	status := logger.Status(LevelInfo, "deploy started")
+	if err := status.Update(LevelInfo, "deploy finished"); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+
+	calls := f.Calls()
+	if len(calls) != 4 {
+		t.Fatalf("expected 4 calls, got %d", len(calls))
+	}
+	want := []struct{ method, channel, ts string }{
+		{"chat.postMessage", "C0123", ""},
+		{"chat.postMessage", "C0456", ""},
+		{"chat.update", "C0123", "1700000000.000001"},
+		{"chat.update", "C0456", "1700000000.000002"},
+	}
+	for i, w := range want {
+		call := calls[i]
+		ts, _ := call.Body["ts"].(string)
+		if call.Method != w.method || call.Body["channel"] != w.channel || ts != w.ts {
+			t.Errorf("call %d: expected %s to %s at %q, got %s %v", i, w.method, w.channel, w.ts, call.Method, call.Body)
+		}
+	}
+}
+
+func TestStatusFallback(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+
+	var buf strings.Builder
+	logger := newAPILogger(f, "C0123")
+	logger.Writer.Fallbacks = map[LogLevel][]Fallback{LevelInfo: {{Writer: &buf}}}
+	f.respond = func(apiCall) (int, map[string]any) {
+		return http.StatusOK, map[string]any{"ok": false, "error": "channel_not_found"}
+	}
+	status := logger.Status(LevelInfo, "deploy started")
+	var ferr *FallbackError
+	if !errors.As(status.Err(), &ferr) {
+		t.Fatalf("expected a *FallbackError, got %v", status.Err())
+	}
+	if !strings.Contains(buf.String(), "INFO: deploy started") {
+		t.Errorf("expected the message in the fallback, got %q", buf.String())
+	}
+}
+
+func TestStatusTruncatesAttachments(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+
+	logger := newAPILogger(f, "C0123")
+	logger.Writer.Formatter = AttachmentFormatter{}
+	logger.Writer.MaxTextLen = 200
+	logger.Writer.Oversize = OversizeSplit
+	logger.Status(LevelInfo, strings.Repeat("x", 1000))
+
+	calls := f.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 call, got %d", len(calls))
+	}
+	a := calls[0].Body["attachments"].([]any)[0].(map[string]any)
+	for _, key := range []string{"text", "fallback"} {
+		if text := a[key].(string); len(text) > 200 {
+			t.Errorf("attachment %s was not truncated: %d bytes", key, len(text))
+		}
+	}
+}
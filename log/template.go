@@ -0,0 +1,112 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateFormatter renders records with a text/template. The template is
+// executed with the Record as its data and can use these functions:
+//
+//	level      full level name, e.g. "WARNING"
+//	tag        short level tag, e.g. "WARN"
+//	emoji      emoji for the level, e.g. ":warning:"
+//	upper      strings.ToUpper
+//	trim       strings.TrimSpace, e.g. for the trailing newline left by Error and the *ln methods
+//	json       JSON encoding of a value, for payload templates
+//	truncate   truncate N s shortens s to at most N bytes
+//	codeblock  wraps a string in a fenced code block
+//	hostname   the host's name
+//
+// Text templates produce the message text. Payload templates produce the full
+// JSON payload, e.g. {"text": {{json .Message}}, "blocks": [...]}.
+type TemplateFormatter struct {
+	tmpl    *template.Template
+	payload bool
+}
+
+// templateFuncs are the helper functions available to templates.
+var templateFuncs = template.FuncMap{
+	"level": func(l LogLevel) string { return levelNames[l] },
+	"tag":   func(l LogLevel) string { return levelTags[l] },
+	"emoji": func(l LogLevel) string { return levelEmoji[l] },
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"truncate":  func(n int, s string) string { return truncate(s, n) },
+	"codeblock": codeBlock,
+	"hostname":  hostname,
+}
+
+// sampleRecord is the Record templates are executed against when parsed,
+// so mistakes surface at startup instead of on the first message.
+var sampleRecord = Record{
+	Level:   LevelError,
+	Message: "template check",
+	Prefix:  "[APP] ",
+	Time:    time.Unix(0, 0),
+	Fields:  []Field{{Key: "key", Value: "value"}},
+	Caller:  "main/main.go:1",
+}
+
+// ParseTemplate returns a TemplateFormatter whose template text produces the message text.
+func ParseTemplate(text string) (*TemplateFormatter, error) {
+	return parseTemplate("message", text, false)
+}
+
+// ParsePayloadTemplate returns a TemplateFormatter whose template text produces
+// the full JSON payload posted to Slack.
+func ParsePayloadTemplate(text string) (*TemplateFormatter, error) {
+	return parseTemplate("payload", text, true)
+}
+
+// ParseTemplateFile reads a template from path. Files with a ".json" extension
+// are payload templates; anything else produces the message text.
+func ParseTemplateFile(path string) (*TemplateFormatter, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("log: template: %w", err)
+	}
+	return parseTemplate(filepath.Base(path), string(b), filepath.Ext(path) == ".json")
+}
+
+// parseTemplate parses text and executes it once against sampleRecord to validate it.
+func parseTemplate(name, text string, payload bool) (*TemplateFormatter, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("log: %w", err)
+	}
+	tf := &TemplateFormatter{tmpl: tmpl, payload: payload}
+	if _, err := tf.Format(sampleRecord); err != nil {
+		return nil, err
+	}
+	return tf, nil
+}
+
+// Format implements Formatter.
+func (tf *TemplateFormatter) Format(r Record) (Payload, error) {
+	var b bytes.Buffer
+	if err := tf.tmpl.Execute(&b, r); err != nil {
+		return Payload{}, fmt.Errorf("log: %w", err)
+	}
+	if !tf.payload {
+		return Payload{Text: b.String()}, nil
+	}
+	var p Payload
+	if err := json.Unmarshal(b.Bytes(), &p); err != nil {
+		return Payload{}, fmt.Errorf("log: template %s: invalid payload: %w", tf.tmpl.Name(), err)
+	}
+	if p.Text == "" && p.plain() {
+		return Payload{}, fmt.Errorf("log: template %s: payload has no text, blocks or attachments", tf.tmpl.Name())
+	}
+	return p, nil
+}
@@ -0,0 +1,130 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTemplateFormatter(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	tf, err := ParseTemplate(`{{emoji .Level}} {{level .Level}} {{.Prefix}}{{upper .Message}}{{range .Fields}} [{{.Key}}={{.Value}}]{{end}}`)
+	if err != nil {
+		t.Fatalf("ParseTemplate returned error: %v", err)
+	}
+	logger := New(srv.URL)
+	logger.SetPrefix("[APP] ")
+	logger.Writer.Formatter = tf
+	logger.Warningw("disk almost full", "mount", "/var")
+
+	msgs := getMessages()
+	if want := ":warning: WARNING [APP] DISK ALMOST FULL [mount=/var]"; len(msgs) != 1 || msgs[0] != want {
+		t.Errorf("expected %q, got %q", want, msgs)
+	}
+}
+
+func TestTemplateHelpers(t *testing.T) {
+	tf, err := ParseTemplate(`{{tag .Level}} {{truncate 8 .Message}}{{"\n"}}{{codeblock .Caller}}`)
+	if err != nil {
+		t.Fatalf("ParseTemplate returned error: %v", err)
+	}
+	p, err := tf.Format(Record{Level: LevelDebug, Message: "hello world", Caller: "main/main.go:7"})
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	if want := "DEBG hello…\n```\nmain/main.go:7\n```"; p.Text != want {
+		t.Errorf("expected %q, got %q", want, p.Text)
+	}
+}
+
+func TestTemplateTruncateSmall(t *testing.T) {
+	tf, err := ParseTemplate(`{{truncate 2 .Message}}`)
+	if err != nil {
+		t.Fatalf("ParseTemplate returned error: %v", err)
+	}
+	if p, _ := tf.Format(Record{Message: "hello"}); p.Text != "he" {
+		t.Errorf("expected %q, got %q", "he", p.Text)
+	}
+}
+
+func TestPayloadTemplate(t *testing.T) {
+	srv, getPayloads := newPayloadServer(t)
+	defer srv.Close()
+
+	tf, err := ParsePayloadTemplate(`{"text": {{json .Message}}, "blocks": [{"type": "section", "text": {"type": "mrkdwn", "text": {{json (printf "*%s* %s" (level .Level) .Message)}}}}]}`)
+	if err != nil {
+		t.Fatalf("ParsePayloadTemplate returned error: %v", err)
+	}
+	logger := New(srv.URL)
+	logger.Writer.Formatter = tf
+	logger.Errorw(`quoted "value"`)
+
+	payloads := getPayloads()
+	if len(payloads) != 1 {
+		t.Fatalf("expected 1 payload, got %d", len(payloads))
+	}
+	if payloads[0]["text"] != `quoted "value"` {
+		t.Errorf("unexpected text: %q", payloads[0]["text"])
+	}
+	if texts := blockTexts(payloads[0]); len(texts) != 1 || texts[0] != `section: *ERROR* quoted "value"` {
+		t.Errorf("unexpected blocks: %q", texts)
+	}
+}
+
+func TestTemplateValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		payload bool
+		want    string
+	}{
+		{name: "syntax", text: `{{.Message`, want: "unclosed action"},
+		{name: "unknown function", text: `{{shout .Message}}`, want: `function "shout" not defined`},
+		{name: "unknown field", text: `{{.Msg}}`, want: "can't evaluate field Msg"},
+		{name: "invalid JSON", text: `{"text": {{.Message}}}`, payload: true, want: "invalid payload"},
+		{name: "empty payload", text: `{}`, payload: true, want: "no text, blocks or attachments"},
+	}
+	for _, test := range tests {
+		parse := ParseTemplate
+		if test.payload {
+			parse = ParsePayloadTemplate
+		}
+		_, err := parse(test.text)
+		if err == nil || !strings.Contains(err.Error(), test.want) || !strings.HasPrefix(err.Error(), "log: template") {
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.want, err)
+		}
+	}
+}
+
+func TestParseTemplateFile(t *testing.T) {
+	dir := t.TempDir()
+	text := filepath.Join(dir, "message.tmpl")
+	payload := filepath.Join(dir, "message.json")
+	if err := os.WriteFile(text, []byte(`{{tag .Level}} {{.Message}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(payload, []byte(`{"text": {{json .Message}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tf, err := ParseTemplateFile(text)
+	if err != nil {
+		t.Fatalf("ParseTemplateFile returned error: %v", err)
+	}
+	if p, _ := tf.Format(Record{Level: LevelInfo, Message: "hi"}); p.Text != "INFO hi" {
+		t.Errorf("unexpected text template output: %q", p.Text)
+	}
+	tf, err = ParseTemplateFile(payload)
+	if err != nil {
+		t.Fatalf("ParseTemplateFile returned error: %v", err)
+	}
+	if p, _ := tf.Format(Record{Message: "hi"}); p.Text != "hi" {
+		t.Errorf("unexpected payload template output: %q", p.Text)
+	}
+	if _, err := ParseTemplateFile(filepath.Join(dir, "missing.tmpl")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
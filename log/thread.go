@@ -0,0 +1,93 @@
+package log
+
+import (
+	"context"
+	"sync"
+)
+
+// thread tracks the parent message of a thread in each channel it was posted to.
+type thread struct {
+	mu sync.Mutex
+	// ts holds the parent message's ts per channel.
+	ts map[string]string
+}
+
+// threads holds the process-wide threads keyed by the name given to Thread or WithThread.
+var threads = struct {
+	sync.Mutex
+	m map[string]*thread
+}{m: make(map[string]*thread)}
+
+// threadFor returns the thread for key, creating it on first use.
+func threadFor(key string) *thread {
+	threads.Lock()
+	defer threads.Unlock()
+	t, ok := threads.m[key]
+	if !ok {
+		t = newThread()
+		threads.m[key] = t
+	}
+	return t
+}
+
+func newThread() *thread {
+	return &thread{ts: make(map[string]string)}
+}
+
+// EndThread forgets the thread for key, so the next message for key starts a new thread.
+func EndThread(key string) {
+	threads.Lock()
+	defer threads.Unlock()
+	delete(threads.m, key)
+}
+
+// threadKey is the context key for the thread messages are posted in.
+type threadKey struct{}
+
+// WithThread returns a copy of ctx that groups the messages logged with it by
+// the *Context methods into the Slack thread named key, as Logger.Thread does.
+func WithThread(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, threadKey{}, threadFor(key))
+}
+
+// threadFrom returns the thread carried by ctx, if any.
+func threadFrom(ctx context.Context) *thread {
+	t, _ := ctx.Value(threadKey{}).(*thread)
+	return t
+}
+
+// Thread returns a child of the default logger that posts into the thread named key.
+func Thread(key string) *Logger {
+	return std.Thread(key)
+}
+
+// Thread returns a child Logger that groups its messages into a Slack thread.
+// The first message for key is posted to the channel; later ones, from any
+// Logger using the same key, are posted as replies to it. Threads need a
+// Web API destination; messages bound for webhooks are posted as usual.
+// The child shares the parent's destinations and async queue but tracks its own error.
+func (l *Logger) Thread(key string) *Logger {
+	child := &Logger{Writer: l.Writer}
+	child.Writer.thread = threadFor(key)
+	return child
+}
+
+// send posts p in channel as part of t. The first message becomes the parent;
+// later ones wait for it and are posted as replies. A parent that was posted
+// is kept even if deliver also reports an error.
+func (t *thread) send(channel string, p Payload, deliver func(Payload) (string, error)) error {
+	t.mu.Lock()
+	if parent := t.ts[channel]; parent != "" {
+		t.mu.Unlock()
+		p.ThreadTS = parent
+		_, err := deliver(p)
+		return err
+	}
+	defer t.mu.Unlock()
+	p.ReplyBroadcast = false
+	ts, err := deliver(p)
+	if ts != "" {
+		t.ts[channel] = ts
+	}
+	return err
+}
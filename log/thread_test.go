@@ -0,0 +1,127 @@
+package log
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestThread(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+	defer EndThread("job-1")
+
+	logger := newAPILogger(f, "C0123")
+	job := logger.Thread("job-1")
+	job.Info("job started")
+	job.Info("step 1 done")
+	logger.Thread("job-1").Error("step 2 failed")
+	logger.Info("unrelated")
+
+	calls := f.Calls()
+	if len(calls) != 4 {
+		t.Fatalf("expected 4 calls, got %d", len(calls))
+	}
+	parent := "1700000000.000001"
+	for i, want := range []any{nil, parent, parent, nil} {
+		if got := calls[i].Body["thread_ts"]; got != want {
+			t.Errorf("call %d: expected thread_ts %v, got %v", i, want, got)
+		}
+		if calls[i].Body["reply_broadcast"] != nil {
+			t.Errorf("call %d: unexpected reply_broadcast", i)
+		}
+	}
+}
+
+func TestThreadBroadcastErrors(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+	defer EndThread("job-2")
+
+	logger := newAPILogger(f, "C0123")
+	logger.Writer.BroadcastErrors = true
+	job := logger.Thread("job-2")
+	job.Error("parent is never broadcast")
+	job.Info("quiet reply")
+	job.Error("loud reply")
+
+	calls := f.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 calls, got %d", len(calls))
+	}
+	for i, want := range []any{nil, nil, true} {
+		if got := calls[i].Body["reply_broadcast"]; got != want {
+			t.Errorf("call %d: expected reply_broadcast %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestWithThread(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+	defer EndThread("request-9")
+
+	logger := newAPILogger(f, "C0123")
+	ctx := WithThread(context.Background(), "request-9")
+	logger.InfoContext(ctx, "request received")
+	logger.WarningContext(ctx, "slow query")
+	EndThread("request-9")
+	logger.InfoContext(WithThread(context.Background(), "request-9"), "new thread")
+
+	calls := f.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 calls, got %d", len(calls))
+	}
+	if calls[0].Body["thread_ts"] != nil || calls[1].Body["thread_ts"] != "1700000000.000001" || calls[2].Body["thread_ts"] != nil {
+		t.Errorf("unexpected threading: %v, %v, %v", calls[0].Body["thread_ts"], calls[1].Body["thread_ts"], calls[2].Body["thread_ts"])
+	}
+}
+
+func TestThreadAsyncRepliesWaitForParent(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+	defer EndThread("job-3")
+
+	logger := newAPILogger(f, "C0123")
+	logger.StartAsync(AsyncOptions{Workers: 4})
+	defer logger.Close()
+	job := logger.Thread("job-3")
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			job.Info("concurrent")
+		}()
+	}
+	wg.Wait()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := logger.Flush(ctx); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+
+	var parents int
+	for _, call := range f.Calls() {
+		if call.Body["thread_ts"] == nil {
+			parents++
+		}
+	}
+	if parents != 1 {
+		t.Errorf("expected exactly 1 parent message, got %d", parents)
+	}
+}
+
+func TestThreadWebhook(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+	defer EndThread("job-4")
+
+	logger := New(srv.URL).Thread("job-4")
+	logger.Info("one")
+	logger.Info("two")
+	if msgs := getMessages(); len(msgs) != 2 {
+		t.Errorf("expected webhook messages to be posted as usual, got %q", msgs)
+	}
+}
@@ -0,0 +1,150 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// uploadSummaryLen is the longest first line kept in the message posted with an uploaded message.
+const uploadSummaryLen = 200
+
+// fileUpload is a file posted along with a payload instead of inline text.
+type fileUpload struct {
+	name string
+	data []byte
+}
+
+// uploadURL is the response of files.getUploadURLExternal.
+type uploadURL struct {
+	UploadURL string `json:"upload_url"`
+	FileID    string `json:"file_id"`
+}
+
+// completeUpload is the body of a files.completeUploadExternal call.
+type completeUpload struct {
+	Files          []uploadedFile `json:"files"`
+	ChannelID      string         `json:"channel_id"`
+	InitialComment string         `json:"initial_comment,omitempty"`
+	ThreadTS       string         `json:"thread_ts,omitempty"`
+}
+
+// uploadedFile identifies a file in a files.completeUploadExternal call.
+type uploadedFile struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// Attach posts a message at level with data attached as a file using the default logger.
+func Attach(level LogLevel, msg, filename string, data []byte, kv ...any) {
+	std.Attach(level, msg, filename, data, kv...)
+}
+
+// Attach posts a message at level, followed by key/value pairs, with data
+// uploaded as a file named filename, e.g. a stack trace, request dump or
+// config diff. Uploads need a Web API destination; for webhooks data is
+// appended to the message in a code block instead.
+func (l *Logger) Attach(level LogLevel, msg, filename string, data []byte, kv ...any) {
+	lw := l.Writer
+	if lw.Level < level {
+		return
+	}
+	ctx := context.Background()
+	r := lw.record(ctx, level, []byte(msg), kv...)
+	dests, err := lw.destinations(r)
+	if err != nil {
+		l.setErr(err)
+		return
+	}
+	l.setErr(lw.fanOut(dests, func(lw LogWriter, dest string) error {
+		return lw.attach(ctx, dest, r, filename, data)
+	}))
+}
+
+// attach posts r to dest with data uploaded as filename.
+func (lw LogWriter) attach(ctx context.Context, dest string, r Record, filename string, data []byte) error {
+	payload, err := lw.format(r)
+	if err != nil {
+		return err
+	}
+	if !isChannel(dest) {
+		return lw.postAll(ctx, r.Level, dest, lw.fit(inlineData(payload, data)))
+	}
+	payload.ReplyBroadcast = lw.BroadcastErrors && r.Level == LevelError
+	payload.file = &fileUpload{name: filename, data: data}
+	return lw.postAll(ctx, r.Level, dest, []Payload{payload})
+}
+
+// inlineData appends data to p in a code block, for destinations that can't
+// take uploads. Block Kit payloads get it as an extra section too, since their
+// text is only shown in notifications.
+func inlineData(p Payload, data []byte) Payload {
+	block := codeBlock(escapeMrkdwn(string(data), false))
+	p.Text += "\n" + block
+	if len(p.Blocks) > 0 {
+		text := mrkdwn(truncateText(block, maxSectionText))
+		p.Blocks = append(slices.Clip(p.Blocks), sectionBlock{Type: "section", Text: &text})
+	}
+	return p
+}
+
+// summarize returns the first line of msg, shortened, to post with the uploaded message.
+func summarize(msg string) string {
+	line, _, _ := strings.Cut(msg, "\n")
+	return truncate(line, uploadSummaryLen) + " (full message attached)"
+}
+
+// upload shares p.file in channel using Slack's external upload flow: it asks
+// for an upload URL, sends the data there, then completes the upload with p's
+// text as the file's comment.
+func (lw LogWriter) upload(ctx context.Context, channel string, p Payload) error {
+	var dest uploadURL
+	form := url.Values{"filename": {p.file.name}, "length": {strconv.Itoa(len(p.file.data))}}
+	if err := lw.callAPIForm(ctx, "files.getUploadURLExternal", form, &dest); err != nil {
+		return err
+	}
+	if dest.UploadURL == "" {
+		return fmt.Errorf("slack api files.getUploadURLExternal: no upload_url in response")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dest.UploadURL, bytes.NewReader(p.file.data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := lw.client().Do(req)
+	if err != nil {
+		return err
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &APIError{Method: "files upload", StatusCode: resp.StatusCode, Code: strings.TrimSpace(string(body))}
+	}
+	return lw.callAPI(ctx, "files.completeUploadExternal", completeUpload{
+		Files:          []uploadedFile{{ID: dest.FileID, Title: p.file.name}},
+		ChannelID:      channel,
+		InitialComment: p.Text,
+		ThreadTS:       p.ThreadTS,
+	}, nil)
+}
+
+// callAPIForm posts form to a Web API method that takes form-encoded arguments.
+func (lw LogWriter) callAPIForm(ctx context.Context, method string, form url.Values, out any) error {
+	if lw.Token == "" {
+		return ErrNoToken
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, lw.apiURL(method), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+lw.Token)
+	return lw.doAPI(req, method, out)
+}
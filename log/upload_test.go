@@ -0,0 +1,174 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAttach(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+
+	logger := newAPILogger(f, "C0123")
+	dump := []byte("GET /orders HTTP/1.1\nHost: <api>\n")
+	logger.Attach(LevelError, "bad request", "request.txt", dump, "status", 400)
+	if err := logger.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	calls := f.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 calls, got %d", len(calls))
+	}
+	if calls[0].Method != "files.getUploadURLExternal" || calls[0].Body["filename"] != "request.txt" || calls[0].Body["length"] != "33" {
+		t.Errorf("unexpected upload URL request: %+v", calls[0])
+	}
+	if calls[1].Method != "/upload/F1" || string(calls[1].Data) != string(dump) {
+		t.Errorf("unexpected upload: %s %q", calls[1].Method, calls[1].Data)
+	}
+	complete := calls[2]
+	if complete.Method != "files.completeUploadExternal" || complete.Body["channel_id"] != "C0123" {
+		t.Fatalf("unexpected completion: %+v", complete)
+	}
+	if complete.Body["initial_comment"] != "ERRO: bad request status=400" {
+		t.Errorf("unexpected comment: %q", complete.Body["initial_comment"])
+	}
+	if files := complete.Body["files"].([]any); files[0].(map[string]any)["id"] != "F1" {
+		t.Errorf("unexpected files: %v", files)
+	}
+}
+
+func TestUploadThreshold(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+
+	logger := newAPILogger(f, "C0123")
+	logger.Writer.UploadThreshold = 100
+	logger.Info("short")
+	trace := "panic: runtime error\n" + strings.Repeat("\tmain.go:42\n", 20)
+	logger.Error(trace)
+
+	calls := f.Calls()
+	if len(calls) != 4 || calls[0].Method != "chat.postMessage" || calls[3].Method != "files.completeUploadExternal" {
+		t.Fatalf("unexpected calls: %+v", calls)
+	}
+	if string(calls[2].Data) != trace+"\n" {
+		t.Errorf("expected the full message uploaded, got %q", calls[2].Data)
+	}
+	if want := "ERRO: panic: runtime error (full message attached)"; calls[3].Body["initial_comment"] != want {
+		t.Errorf("expected comment %q, got %q", want, calls[3].Body["initial_comment"])
+	}
+}
+
+func TestUploadInThread(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+	defer EndThread("upload-job")
+
+	logger := newAPILogger(f, "C0123")
+	logger.StartAsync(AsyncOptions{})
+	defer logger.Close()
+	job := logger.Thread("upload-job")
+	job.Info("job started")
+	job.Attach(LevelInfo, "report", "report.csv", []byte("a,b\n1,2\n"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := logger.Flush(ctx); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+
+	calls := f.Calls()
+	if len(calls) != 4 {
+		t.Fatalf("expected 4 calls, got %d", len(calls))
+	}
+	if calls[3].Body["thread_ts"] != "1700000000.000001" {
+		t.Errorf("expected the upload in the thread, got %v", calls[3].Body["thread_ts"])
+	}
+}
+
+func TestAttachWebhook(t *testing.T) {
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Attach(LevelWarning, "config changed", "diff.txt", []byte("- a: <1>\n+ a: 2\n"))
+	want := "WARN: config changed\n```\n- a: &lt;1&gt;\n+ a: 2\n```"
+	if msgs := getMessages(); len(msgs) != 1 || msgs[0] != want {
+		t.Errorf("expected %q, got %q", want, msgs)
+	}
+}
+
+func TestUploadFails(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+	f.respond = func(call apiCall) (int, map[string]any) {
+		if strings.HasPrefix(call.Method, "/upload/") {
+			return http.StatusInternalServerError, nil
+		}
+		return http.StatusOK, map[string]any{"ok": true, "upload_url": f.Server.URL + "/upload/F1", "file_id": "F1"}
+	}
+
+	logger := newAPILogger(f, "C0123")
+	logger.Attach(LevelError, "dump", "dump.bin", []byte{1, 2, 3})
+	var aerr *APIError
+	if !errors.As(logger.Err(), &aerr) || aerr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected a 500 *APIError, got %v", logger.Err())
+	}
+}
+
+func TestUploadStartsThread(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+	defer EndThread("upload-parent")
+
+	logger := newAPILogger(f, "C0123")
+	logger.Writer.UploadThreshold = 100
+	job := logger.Thread("upload-parent")
+	job.Error("panic: runtime error\n" + strings.Repeat("\tmain.go:42\n", 20))
+	job.Info("restarted")
+	if err := job.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	calls := f.Calls()
+	var methods []string
+	for _, call := range calls {
+		methods = append(methods, call.Method)
+	}
+	want := []string{"chat.postMessage", "files.getUploadURLExternal", "/upload/F2", "files.completeUploadExternal", "chat.postMessage"}
+	if strings.Join(methods, " ") != strings.Join(want, " ") {
+		t.Fatalf("expected calls %q, got %q", want, methods)
+	}
+	parent := "1700000000.000001"
+	if calls[0].Body["thread_ts"] != nil || !strings.HasSuffix(calls[0].Body["text"].(string), "(full message attached)") {
+		t.Errorf("unexpected parent message: %v", calls[0].Body)
+	}
+	if calls[3].Body["thread_ts"] != parent || calls[4].Body["thread_ts"] != parent {
+		t.Errorf("expected the upload and the next message in the thread, got %v and %v", calls[3].Body["thread_ts"], calls[4].Body["thread_ts"])
+	}
+}
+
+func TestAttachWebhookBlocks(t *testing.T) {
+	srv, getPayloads := newPayloadServer(t)
+	defer srv.Close()
+
+	logger := New(srv.URL)
+	logger.Writer.Formatter = BlockFormatter{}
+	logger.Attach(LevelError, "boom", "dump.txt", []byte("goroutine 1 [running]:\n"))
+
+	payloads := getPayloads()
+	if len(payloads) != 1 {
+		t.Fatalf("expected 1 payload, got %d", len(payloads))
+	}
+	texts := blockTexts(payloads[0])
+	if want := "section: ```\ngoroutine 1 [running]:\n```"; texts[len(texts)-1] != want {
+		t.Errorf("expected the data in a section block %q, got %q", want, texts)
+	}
+	if !strings.Contains(payloads[0]["text"].(string), "goroutine 1 [running]:") {
+		t.Errorf("expected the data in the fallback text, got %q", payloads[0]["text"])
+	}
+}
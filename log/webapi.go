@@ -0,0 +1,139 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the base URL of the Slack Web API used when LogWriter.APIURL is empty.
+const DefaultAPIURL = "https://slack.com/api/"
+
+// ErrNoToken is returned when a message is bound for a channel but the LogWriter has no Token.
+var ErrNoToken = errors.New("log: no Slack bot token")
+
+// APIError is returned when a Slack Web API call fails, either with a non-2xx
+// status or with {"ok": false}. Code holds Slack's error string, e.g.
+// "channel_not_found", "not_in_channel", "invalid_auth" or "ratelimited".
+type APIError struct {
+	Method string
+	// StatusCode is the HTTP status; it is 200 for {"ok": false} responses.
+	StatusCode int
+	Code       string
+	// RetryAfter is the delay Slack asked for via the Retry-After header, if any.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("slack api %s: %d %s", e.Method, e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("slack api %s: %s", e.Method, e.Code)
+}
+
+// retryableAPICodes are the {"ok": false} error strings worth retrying.
+var retryableAPICodes = []string{"ratelimited", "internal_error", "fatal_error", "service_unavailable", "request_timeout"}
+
+// isChannel reports whether dest is a channel ID for the Web API rather than a webhook URL.
+func isChannel(dest string) bool {
+	return dest != "" && !strings.Contains(dest, "://")
+}
+
+// apiURL returns the URL of a Web API method.
+func (lw LogWriter) apiURL(method string) string {
+	base := lw.APIURL
+	if base == "" {
+		base = DefaultAPIURL
+	}
+	return strings.TrimSuffix(base, "/") + "/" + method
+}
+
+// apiStatus is the part of every Web API response that reports success.
+type apiStatus struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// callAPI posts body as JSON to a Web API method with the writer's token and
+// decodes the response into out, if non-nil.
+// Returns an *APIError if Slack answered with an error.
+func (lw LogWriter) callAPI(ctx context.Context, method string, body, out any) error {
+	if lw.Token == "" {
+		return ErrNoToken
+	}
+	jsonValue, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, lw.apiURL(method), bytes.NewReader(jsonValue))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+lw.Token)
+	return lw.doAPI(req, method, out)
+}
+
+// doAPI sends req and decodes the response of a Web API method into out, if non-nil.
+func (lw LogWriter) doAPI(req *http.Request, method string, out any) error {
+	resp, err := lw.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return &APIError{
+			Method:     method,
+			StatusCode: resp.StatusCode,
+			Code:       strings.TrimSpace(string(body)),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var status apiStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return fmt.Errorf("slack api %s: %w", method, err)
+	}
+	if !status.OK {
+		return &APIError{
+			Method:     method,
+			StatusCode: resp.StatusCode,
+			Code:       status.Error,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
+
+// postMessage is the body of a chat.postMessage call.
+type postMessage struct {
+	Channel string `json:"channel"`
+	Payload
+}
+
+// postedMessage is the part of a chat.postMessage response identifying the message.
+type postedMessage struct {
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+}
+
+// postAPI posts p to channel with chat.postMessage.
+func (lw LogWriter) postAPI(ctx context.Context, channel string, p Payload) (postedMessage, error) {
+	var resp postedMessage
+	err := lw.callAPI(ctx, "chat.postMessage", postMessage{Channel: channel, Payload: p}, &resp)
+	return resp, err
+}
@@ -0,0 +1,197 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// apiCall is a request received by a fakeSlack server. Form and JSON bodies
+// are decoded into Body; uploaded file contents are kept in Data.
+type apiCall struct {
+	Method string
+	Auth   string
+	Body   map[string]any
+	Data   []byte
+}
+
+// fakeSlack is a local stand-in for the Slack Web API. It records every call
+// and answers with respond, or with {"ok": true} and a fresh ts by default.
+type fakeSlack struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	calls   []apiCall
+	respond func(call apiCall) (status int, resp map[string]any)
+}
+
+func newFakeSlack(t *testing.T) *fakeSlack {
+	t.Helper()
+	f := &fakeSlack{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := apiCall{Method: strings.TrimPrefix(r.URL.Path, "/api/"), Auth: r.Header.Get("Authorization")}
+		body, _ := io.ReadAll(r.Body)
+		switch r.Header.Get("Content-Type") {
+		case "application/x-www-form-urlencoded":
+			form, _ := url.ParseQuery(string(body))
+			call.Body = make(map[string]any)
+			for key := range form {
+				call.Body[key] = form.Get(key)
+			}
+		case "application/octet-stream":
+			call.Data = body
+		default:
+			if err := json.Unmarshal(body, &call.Body); err != nil {
+				t.Errorf("unmarshaling %s body: %v", call.Method, err)
+			}
+		}
+		f.mu.Lock()
+		f.calls = append(f.calls, call)
+		n := len(f.calls)
+		respond := f.respond
+		f.mu.Unlock()
+
+		status, resp := http.StatusOK, map[string]any{"ok": true, "channel": call.Body["channel"], "ts": fmt.Sprintf("1700000000.%06d", n)}
+		if call.Method == "files.getUploadURLExternal" {
+			resp = map[string]any{"ok": true, "upload_url": f.Server.URL + "/upload/F" + strconv.Itoa(n), "file_id": "F" + strconv.Itoa(n)}
+		}
+		if respond != nil {
+			status, resp = respond(call)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(resp)
+	}))
+	return f
+}
+
+// URL returns the base API URL to set as LogWriter.APIURL.
+func (f *fakeSlack) URL() string {
+	return f.Server.URL + "/api/"
+}
+
+// Calls returns the calls received so far.
+func (f *fakeSlack) Calls() []apiCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cp := make([]apiCall, len(f.calls))
+	copy(cp, f.calls)
+	return cp
+}
+
+// newAPILogger returns a Logger that posts every level to channel through f.
+func newAPILogger(f *fakeSlack, channel string) *Logger {
+	logger := New(channel)
+	logger.Writer.Token = "xoxb-test"
+	logger.Writer.APIURL = f.URL()
+	return logger
+}
+
+func TestPostMessage(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+
+	logger := newAPILogger(f, "C0123")
+	logger.Writer.Formatter = BlockFormatter{}
+	logger.Errorw("deploy failed", "service", "api")
+	if err := logger.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	calls := f.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 call, got %d", len(calls))
+	}
+	call := calls[0]
+	if call.Method != "chat.postMessage" || call.Auth != "Bearer xoxb-test" || call.Body["channel"] != "C0123" {
+		t.Errorf("unexpected call: %+v", call)
+	}
+	if call.Body["text"] != "ERRO: deploy failed service=api" {
+		t.Errorf("unexpected text: %q", call.Body["text"])
+	}
+	if blocks, _ := call.Body["blocks"].([]any); len(blocks) != 3 {
+		t.Errorf("expected 3 blocks, got %v", call.Body["blocks"])
+	}
+}
+
+func TestPostMessagePerLevel(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+	srv, getMessages := newTestServer(t)
+	defer srv.Close()
+
+	logger := newAPILogger(f, srv.URL)
+	logger.Writer.Error = "C0ERRORS"
+	logger.Info("to the webhook")
+	logger.Error("to the channel")
+
+	if msgs := getMessages(); len(msgs) != 1 || msgs[0] != "INFO: to the webhook" {
+		t.Errorf("unexpected webhook messages: %q", msgs)
+	}
+	if calls := f.Calls(); len(calls) != 1 || calls[0].Body["channel"] != "C0ERRORS" {
+		t.Errorf("unexpected API calls: %+v", calls)
+	}
+}
+
+func TestPostMessageNotOK(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+	f.respond = func(apiCall) (int, map[string]any) {
+		return http.StatusOK, map[string]any{"ok": false, "error": "channel_not_found"}
+	}
+
+	logger := newAPILogger(f, "C0MISSING")
+	logger.Writer.Retry = RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond}
+	logger.Info("lost")
+	var aerr *APIError
+	if !errors.As(logger.Err(), &aerr) || aerr.Code != "channel_not_found" || aerr.Method != "chat.postMessage" {
+		t.Fatalf("expected channel_not_found *APIError, got %v", logger.Err())
+	}
+	if got := len(f.Calls()); got != 1 {
+		t.Errorf("expected permanent API errors not to be retried, got %d calls", got)
+	}
+}
+
+func TestPostMessageRetry(t *testing.T) {
+	f := newFakeSlack(t)
+	defer f.Close()
+	var n int
+	f.respond = func(call apiCall) (int, map[string]any) {
+		n++
+		switch n {
+		case 1:
+			return http.StatusTooManyRequests, map[string]any{"ok": false, "error": "ratelimited"}
+		case 2:
+			return http.StatusOK, map[string]any{"ok": false, "error": "internal_error"}
+		}
+		return http.StatusOK, map[string]any{"ok": true}
+	}
+
+	logger := newAPILogger(f, "C0123")
+	logger.Writer.Retry = RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond}
+	logger.Info("eventually delivered")
+	if err := logger.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(f.Calls()); got != 3 {
+		t.Errorf("expected 3 calls, got %d", got)
+	}
+}
+
+func TestPostMessageNoToken(t *testing.T) {
+	logger := New("C0123")
+	logger.Writer.Retry = RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond}
+	logger.Info("nowhere to go")
+	if !errors.Is(logger.Err(), ErrNoToken) {
+		t.Errorf("expected ErrNoToken, got %v", logger.Err())
+	}
+}